@@ -0,0 +1,368 @@
+// Package analyzer provides an analysis.Analyzer that checks the queries scanned by goscanql
+// against the sql tags of the types that they are scanned into.
+package analyzer
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const goscanqlPath = "github.com/rustedturnip/goscanql"
+
+// scanFuncs are the functions of goscanql whose calls are checked.
+var scanFuncs = map[string]bool{
+	"RowsToStructs": true,
+	"RowsToStruct":  true,
+}
+
+// queryFuncs maps the name of each method that produces rows to the position of its query
+// argument, e.g. db.QueryContext(ctx, query).
+var queryFuncs = map[string]int{
+	"Query":        0,
+	"QueryContext": 1,
+}
+
+// Analyzer reports the mismatches between the columns selected by a constant query, and the
+// columns that goscanql maps to the type that its rows are scanned into (by RowsToStructs or
+// RowsToStruct), i.e. columns that map to no field, and fields that no column maps to.
+//
+// Calls that provide options are not checked, as the options may change the way in which columns
+// are mapped (e.g. WithNameMapper).
+var Analyzer = &analysis.Analyzer{
+	Name:     "goscanql",
+	Doc:      "check that the columns of queries scanned by goscanql match the sql tags of their types",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	insp.WithStack([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node, push bool, stack []ast.Node) bool {
+		if !push {
+			return true
+		}
+
+		call := n.(*ast.CallExpr)
+
+		t, ok := scannedType(pass, call)
+		if !ok || len(call.Args) != 1 {
+			return true
+		}
+
+		body := enclosingBody(stack)
+		if body == nil {
+			return true
+		}
+
+		query, ok := queryOf(pass, body, call.Args[0])
+		if !ok {
+			return true
+		}
+
+		list, ok := parseSelect(constant.StringVal(query.value))
+		if !ok {
+			return true
+		}
+
+		check(pass, call, query.expr, t, list)
+		return true
+	})
+
+	return nil, nil
+}
+
+// scannedType returns the type that the provided call scans rows into, if it is a call of one of
+// the scanFuncs of goscanql.
+func scannedType(pass *analysis.Pass, call *ast.CallExpr) (types.Type, bool) {
+	fun := ast.Unparen(call.Fun)
+
+	switch f := fun.(type) {
+	case *ast.IndexExpr:
+		fun = f.X
+	case *ast.IndexListExpr:
+		fun = f.X
+	}
+
+	var ident *ast.Ident
+
+	switch f := fun.(type) {
+	case *ast.Ident:
+		ident = f
+	case *ast.SelectorExpr:
+		ident = f.Sel
+	default:
+		return nil, false
+	}
+
+	fn, ok := pass.TypesInfo.Uses[ident].(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != goscanqlPath || !scanFuncs[fn.Name()] {
+		return nil, false
+	}
+
+	instance, ok := pass.TypesInfo.Instances[ident]
+	if !ok || instance.TypeArgs.Len() != 1 {
+		return nil, false
+	}
+
+	return instance.TypeArgs.At(0), true
+}
+
+// enclosingBody returns the body of the innermost function of the provided stack.
+func enclosingBody(stack []ast.Node) *ast.BlockStmt {
+	for i := len(stack) - 1; i >= 0; i-- {
+		switch f := stack[i].(type) {
+		case *ast.FuncDecl:
+			return f.Body
+		case *ast.FuncLit:
+			return f.Body
+		}
+	}
+
+	return nil
+}
+
+// constantQuery holds a constant query, and the expression that it is provided by.
+type constantQuery struct {
+	expr  ast.Expr
+	value constant.Value
+}
+
+// queryOf returns the constant query that the provided rows were produced by, where the rows are
+// held by a variable that is assigned (once) by a call of one of the queryFuncs within body.
+func queryOf(pass *analysis.Pass, body *ast.BlockStmt, rows ast.Expr) (constantQuery, bool) {
+	rhs, ok := assignedValue(pass, body, rows)
+	if !ok {
+		return constantQuery{}, false
+	}
+
+	call, ok := ast.Unparen(rhs).(*ast.CallExpr)
+	if !ok {
+		return constantQuery{}, false
+	}
+
+	sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+	if !ok {
+		return constantQuery{}, false
+	}
+
+	pos, ok := queryFuncs[sel.Sel.Name]
+	if !ok || len(call.Args) <= pos {
+		return constantQuery{}, false
+	}
+
+	return constantOf(pass, body, call.Args[pos])
+}
+
+// constantOf returns the constant string value of the provided expression, which is either a
+// constant expression itself, or a variable that is assigned (once) a constant within body.
+func constantOf(pass *analysis.Pass, body *ast.BlockStmt, expr ast.Expr) (constantQuery, bool) {
+	if tv, ok := pass.TypesInfo.Types[expr]; ok && tv.Value != nil && tv.Value.Kind() == constant.String {
+		return constantQuery{expr: expr, value: tv.Value}, true
+	}
+
+	rhs, ok := assignedValue(pass, body, expr)
+	if !ok {
+		return constantQuery{}, false
+	}
+
+	if tv, ok := pass.TypesInfo.Types[rhs]; ok && tv.Value != nil && tv.Value.Kind() == constant.String {
+		return constantQuery{expr: rhs, value: tv.Value}, true
+	}
+
+	return constantQuery{}, false
+}
+
+// assignedValue returns the expression that is assigned to the variable referred to by the
+// provided expression (expr) within body, where it is assigned exactly once. Where the value is
+// one of many results of a call (e.g. rows, err := db.Query(query)), the call is returned.
+func assignedValue(pass *analysis.Pass, body *ast.BlockStmt, expr ast.Expr) (ast.Expr, bool) {
+	ident, ok := ast.Unparen(expr).(*ast.Ident)
+	if !ok {
+		return nil, false
+	}
+
+	obj, ok := pass.TypesInfo.Uses[ident].(*types.Var)
+	if !ok {
+		return nil, false
+	}
+
+	var values []ast.Expr
+
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch s := n.(type) {
+		case *ast.AssignStmt:
+			values = append(values, assignmentsTo(pass, obj, s.Lhs, s.Rhs)...)
+		case *ast.ValueSpec:
+			lhs := make([]ast.Expr, len(s.Names))
+			for i, name := range s.Names {
+				lhs[i] = name
+			}
+
+			values = append(values, assignmentsTo(pass, obj, lhs, s.Values)...)
+		}
+
+		return true
+	})
+
+	if len(values) != 1 {
+		return nil, false
+	}
+
+	return values[0], true
+}
+
+// assignmentsTo returns the values (of rhs) that are assigned to the provided variable (obj) by
+// the provided assignment.
+func assignmentsTo(pass *analysis.Pass, obj *types.Var, lhs, rhs []ast.Expr) []ast.Expr {
+	values := make([]ast.Expr, 0)
+
+	for i, l := range lhs {
+		ident, ok := l.(*ast.Ident)
+		if !ok || pass.TypesInfo.ObjectOf(ident) != obj {
+			continue
+		}
+
+		switch {
+		case len(rhs) == len(lhs):
+			values = append(values, rhs[i])
+		case len(rhs) == 1:
+			values = append(values, rhs[0])
+		}
+	}
+
+	return values
+}
+
+// check reports each of the mismatches between the columns selected by the query (list), and the
+// columns that goscanql maps to the provided type (t).
+func check(pass *analysis.Pass, call *ast.CallExpr, query ast.Expr, t types.Type, list selectList) {
+	columns, e := columnsOf(t)
+	name := typeName(derefAll(t))
+
+	known := make(map[string]bool, len(columns))
+	for _, c := range columns {
+		known[c.name] = true
+	}
+
+	selected := make(map[string]bool, len(list.aliases))
+	suggested := make(map[string]bool)
+
+	for _, alias := range list.aliases {
+		selected[alias] = true
+
+		if known[alias] || e.collects(alias) {
+			continue
+		}
+
+		suggestion, ok := closest(alias, columns)
+		if ok {
+			suggested[suggestion] = true
+			pass.Reportf(query.Pos(), "column %q does not map to a field of %s (did you mean %q?)", alias, name, suggestion)
+			continue
+		}
+
+		if c, ok := longestPrefix(alias, columns); ok {
+			pass.Reportf(query.Pos(), "column %q does not map to a field of %s (%s has no field tagged %q)", alias, name, c.owner, alias[len(c.prefix)+1:])
+			continue
+		}
+
+		pass.Reportf(query.Pos(), "column %q does not map to a field of %s", alias, name)
+	}
+
+	// where any of the columns can't be named, it can't be known which fields are missing
+	if !list.complete {
+		return
+	}
+
+	for _, c := range columns {
+		if selected[c.name] || suggested[c.name] {
+			continue
+		}
+
+		pass.Reportf(call.Pos(), "field %s (column %q) is not selected by the query", c.field, c.name)
+	}
+}
+
+// closest returns the name of the column that the provided alias is most likely a typo of, if
+// there is one that is close enough.
+func closest(alias string, columns []column) (string, bool) {
+	best, bestDistance := "", -1
+
+	for _, c := range columns {
+		d := distance(alias, c.name)
+
+		if d <= maxDistance(c.name) && (bestDistance < 0 || d < bestDistance) {
+			best, bestDistance = c.name, d
+		}
+	}
+
+	return best, bestDistance >= 0
+}
+
+// longestPrefix returns a column of the struct that the provided alias most likely refers to, i.e.
+// the column with the longest prefix that the alias starts with (if any).
+func longestPrefix(alias string, columns []column) (column, bool) {
+	best, ok := column{}, false
+
+	for _, c := range columns {
+		if c.prefix == "" || !strings.HasPrefix(alias, c.prefix+"_") {
+			continue
+		}
+
+		if !ok || len(c.prefix) > len(best.prefix) {
+			best, ok = c, true
+		}
+	}
+
+	return best, ok
+}
+
+// maxDistance returns the greatest edit distance at which an alias is considered to be a typo of
+// the provided column name.
+func maxDistance(name string) int {
+	if len(name) < 6 {
+		return 1
+	}
+
+	return 2
+}
+
+// distance returns the edit distance between a and b (where the transposition of two adjacent
+// characters is a single edit).
+func distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+
+	return d[len(ra)][len(rb)]
+}
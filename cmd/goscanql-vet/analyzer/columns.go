@@ -0,0 +1,256 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/types"
+	"reflect"
+	"strings"
+)
+
+const (
+	scanqlTag = "sql"
+
+	// extraTagOption is the tag option of a field that collects the columns that aren't mapped.
+	extraTagOption = "extra"
+)
+
+// column represents a single column that goscanql would map to a field.
+type column struct {
+
+	// name is the full name of the column, e.g. pets_name.
+	name string
+
+	// field is the description of the field that the column maps to, e.g. Pet.Name.
+	field string
+
+	// prefix is the prefix of the column (empty for the fields of the root), e.g. pets.
+	prefix string
+
+	// owner is the name of the type that holds the field, e.g. Pet.
+	owner string
+}
+
+// entities maps the prefix of each entity of a type (empty for the root) to whether the entity has
+// an extra field, which collects the columns of the entity that aren't mapped to any other field.
+type entities map[string]bool
+
+// collects returns true if the provided column (which isn't mapped to a field) is collected by an
+// extra field, i.e. the entity at the deepest nesting level whose prefix it has has an extra field.
+func (e entities) collects(column string) bool {
+	owner := ""
+
+	for prefix := range e {
+		if len(prefix) > len(owner) && strings.HasPrefix(column, prefix+"_") {
+			owner = prefix
+		}
+	}
+
+	return e[owner]
+}
+
+// columnsOf returns each of the columns that goscanql would map to the provided type (t), in the
+// order that their fields are declared, along with the entities of the type. Only sql tags are
+// considered (as the options that the type is scanned with can't be known statically).
+func columnsOf(t types.Type) ([]column, entities) {
+	columns := make([]column, 0)
+	e := make(entities)
+	addColumns(t, "", map[types.Type]bool{}, &columns, e)
+
+	return columns, e
+}
+
+// addColumns adds the columns of the provided type (t) to columns, each with the provided prefix,
+// and adds the entity of the type (and of each of its children) to e.
+func addColumns(t types.Type, prefix string, seen map[types.Type]bool, columns *[]column, e entities) {
+	t = derefAll(t)
+
+	st, ok := t.Underlying().(*types.Struct)
+	if !ok || seen[t] {
+		return
+	}
+
+	seen[t] = true
+	defer delete(seen, t)
+
+	e[prefix] = false
+
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+
+		raw, ok := reflect.StructTag(st.Tag(i)).Lookup(scanqlTag)
+		if !ok || raw == "-" {
+			continue
+		}
+
+		parts, _ := splitTag(raw)
+		if hasOption(parts[1:], extraTagOption) {
+			e[prefix] = true
+			continue
+		}
+
+		name := buildReferenceName(prefix, parts[0])
+		fieldType := derefAll(field.Type())
+
+		switch {
+		case isValue(fieldType):
+			*columns = append(*columns, newColumn(name, prefix, t, field))
+
+		case isStruct(fieldType):
+			addColumns(fieldType, name, seen, columns, e)
+
+		default:
+			elem := derefAll(fieldType.Underlying().(*types.Slice).Elem())
+
+			if isValue(elem) {
+				*columns = append(*columns, newColumn(name, prefix, t, field))
+				continue
+			}
+
+			addColumns(elem, name, seen, columns, e)
+		}
+	}
+}
+
+// splitTag splits the raw value of an sql tag on each of the commas that aren't within
+// parentheses or single quotes. It is a copy of goscanql's splitTag, which
+// TestSplitTag_MatchesGoscanql keeps it in step with. An error is returned (along with the parts as
+// far as they could be split) where the raw value has an unterminated quote or unbalanced
+// parentheses.
+func splitTag(raw string) ([]string, error) {
+	parts := make([]string, 0, 1)
+	depth := 0
+	quoted := false
+	start := 0
+
+	for i := 0; i < len(raw); i++ {
+		switch raw[i] {
+		case '\'':
+			// a quote within a quoted value is escaped by doubling it, which toggles twice
+			quoted = !quoted
+		case '(':
+			if !quoted {
+				depth++
+			}
+		case ')':
+			if !quoted {
+				depth--
+				if depth < 0 {
+					return append(parts, raw[start:]), fmt.Errorf("unbalanced parentheses in tag %q", raw)
+				}
+			}
+		case ',':
+			if !quoted && depth == 0 {
+				parts = append(parts, raw[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	parts = append(parts, raw[start:])
+
+	if quoted {
+		return parts, fmt.Errorf("unterminated quote in tag %q", raw)
+	}
+
+	if depth != 0 {
+		return parts, fmt.Errorf("unbalanced parentheses in tag %q", raw)
+	}
+
+	return parts, nil
+}
+
+// hasOption returns true if the provided tag options include the named option.
+func hasOption(options []string, name string) bool {
+	for _, option := range options {
+		if key, _, _ := strings.Cut(option, "="); strings.TrimSpace(key) == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// newColumn creates a new column with the provided name and prefix, for the provided field of the
+// struct type (t).
+func newColumn(name, prefix string, t types.Type, field *types.Var) column {
+	return column{
+		name:   name,
+		field:  typeName(t) + "." + field.Name(),
+		prefix: prefix,
+		owner:  typeName(t),
+	}
+}
+
+// buildReferenceName joins the provided prefix and name in the same way as goscanql.
+func buildReferenceName(prefix, name string) string {
+	parts := make([]string, 0, 2)
+
+	if prefix != "" {
+		parts = append(parts, prefix)
+	}
+
+	if name != "" {
+		parts = append(parts, name)
+	}
+
+	return strings.Join(parts, "_")
+}
+
+// isValue returns true if a field of the provided type (t) is mapped to a single column, i.e. it
+// is a Scanner, a time.Time, decoded by UnmarshalText or UnmarshalBinary, or neither a struct nor
+// a slice.
+func isValue(t types.Type) bool {
+	if isScanner(t) || isTime(t) || isUnmarshaler(t) {
+		return true
+	}
+
+	switch t.Underlying().(type) {
+	case *types.Struct, *types.Slice:
+		return false
+	}
+
+	return true
+}
+
+// isStruct returns true if the underlying type of t is a struct.
+func isStruct(t types.Type) bool {
+	_, ok := t.Underlying().(*types.Struct)
+	return ok
+}
+
+// isTime returns true if t is time.Time.
+func isTime(t types.Type) bool {
+	named, ok := t.(*types.Named)
+	return ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == "time" && named.Obj().Name() == "Time"
+}
+
+// isScanner returns true if t (or a pointer to t) implements goscanql.Scanner, i.e. has both
+// Scan(interface{}) error and ID() []byte methods.
+func isScanner(t types.Type) bool {
+	methods := types.NewMethodSet(types.NewPointer(t))
+	return methods.Lookup(nil, "Scan") != nil && methods.Lookup(nil, "ID") != nil
+}
+
+// isUnmarshaler returns true if t (or a pointer to t) implements encoding.TextUnmarshaler or
+// encoding.BinaryUnmarshaler, i.e. has an UnmarshalText or UnmarshalBinary method.
+func isUnmarshaler(t types.Type) bool {
+	methods := types.NewMethodSet(types.NewPointer(t))
+	return methods.Lookup(nil, "UnmarshalText") != nil || methods.Lookup(nil, "UnmarshalBinary") != nil
+}
+
+// derefAll returns the type that t points to, through any number of pointers.
+func derefAll(t types.Type) types.Type {
+	for {
+		p, ok := t.(*types.Pointer)
+		if !ok {
+			return t
+		}
+
+		t = p.Elem()
+	}
+}
+
+// typeName returns the name of the provided type (t), without its package.
+func typeName(t types.Type) string {
+	return types.TypeString(t, func(*types.Package) string { return "" })
+}
@@ -0,0 +1,54 @@
+package analyzer
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	gotoken "go/token"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// funcSource returns the formatted signature and body (but not the doc comment) of the named
+// function in the provided file.
+func funcSource(t *testing.T, path, name string) string {
+	fset := gotoken.NewFileSet()
+
+	file, err := parser.ParseFile(fset, path, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Name.Name != name {
+			continue
+		}
+
+		var buf bytes.Buffer
+
+		err := format.Node(&buf, fset, &ast.FuncDecl{Name: fn.Name, Type: fn.Type, Body: fn.Body})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return buf.String()
+	}
+
+	t.Fatalf("function %s not found in %s", name, path)
+	return ""
+}
+
+func TestSplitTag_MatchesGoscanql(t *testing.T) {
+	// Arrange
+	expected := funcSource(t, filepath.Join("..", "..", "..", "tag.go"), "splitTag")
+
+	// Act
+	actual := funcSource(t, "columns.go", "splitTag")
+
+	// Assert
+	assert.Equal(t, expected, actual, "splitTag has diverged from goscanql's splitTag")
+}
@@ -0,0 +1,289 @@
+package analyzer
+
+import (
+	"strings"
+	"unicode"
+)
+
+// selectList holds the names of the columns produced by the SELECT of a query.
+type selectList struct {
+
+	// aliases are the names of the columns, in the order that they are selected.
+	aliases []string
+
+	// complete determines whether the name of every column is known, i.e. the query selects no
+	// wildcards (*) and no expressions without an alias.
+	complete bool
+}
+
+// token represents a single lexical token of a query.
+type token struct {
+
+	// text is the text of the token, with the quotes of a quoted identifier removed.
+	text string
+
+	// kind is the kind of the token.
+	kind tokenKind
+
+	// depth is the depth of parentheses that the token appears at.
+	depth int
+}
+
+// tokenKind represents the kind of a token.
+type tokenKind int
+
+const (
+	identToken tokenKind = iota
+	quotedToken
+	stringToken
+	numberToken
+	punctToken
+)
+
+// reserved holds the keywords that can end an expression, and so can't be taken to be an
+// implicit alias.
+var reserved = map[string]bool{
+	"END": true, "NULL": true, "TRUE": true, "FALSE": true, "DISTINCT": true, "ALL": true,
+}
+
+// parseSelect parses the names of the columns selected by the outermost SELECT of the provided
+// query, returning false if the query isn't a SELECT.
+func parseSelect(query string) (selectList, bool) {
+	tokens := tokenize(query)
+
+	start := -1
+
+	for i, t := range tokens {
+		if t.depth == 0 && t.kind == identToken && strings.EqualFold(t.text, "SELECT") {
+			start = i + 1
+			break
+		}
+	}
+
+	if start < 0 {
+		return selectList{}, false
+	}
+
+	if start < len(tokens) && tokens[start].kind == identToken {
+		switch strings.ToUpper(tokens[start].text) {
+		case "DISTINCT", "ALL":
+			start++
+		}
+	}
+
+	list := selectList{complete: true}
+	item := make([]token, 0)
+
+	for i := start; i <= len(tokens); i++ {
+		end := i == len(tokens)
+
+		if !end {
+			t := tokens[i]
+			end = t.depth == 0 && (t.kind == identToken && isClauseKeyword(t.text) || t.kind == punctToken && t.text == ";")
+
+			if !end && !(t.depth == 0 && t.kind == punctToken && t.text == ",") {
+				item = append(item, t)
+				continue
+			}
+		}
+
+		alias, ok := aliasOf(item)
+		if ok {
+			list.aliases = append(list.aliases, alias)
+		} else if len(item) > 0 {
+			list.complete = false
+		}
+
+		item = item[:0]
+
+		if end {
+			break
+		}
+	}
+
+	return list, true
+}
+
+// isClauseKeyword returns true if the provided word ends the select list of a query.
+func isClauseKeyword(word string) bool {
+	switch strings.ToUpper(word) {
+	case "FROM", "WHERE", "GROUP", "HAVING", "ORDER", "LIMIT", "UNION", "INTERSECT", "EXCEPT", "INTO":
+		return true
+	}
+
+	return false
+}
+
+// aliasOf returns the name of the column produced by the provided item of a select list, and
+// whether the name can be determined.
+func aliasOf(item []token) (string, bool) {
+	n := len(item)
+	if n == 0 {
+		return "", false
+	}
+
+	last := item[n-1]
+
+	if last.depth != 0 || last.kind != identToken && last.kind != quotedToken {
+		return "", false
+	}
+
+	if n == 1 {
+		return last.text, !reserved[strings.ToUpper(last.text)] || last.kind == quotedToken
+	}
+
+	prev := item[n-2]
+
+	switch {
+	// explicit alias, e.g. COUNT(*) AS total
+	case prev.depth == 0 && prev.kind == identToken && strings.EqualFold(prev.text, "AS"):
+		return last.text, true
+
+	// qualified column, e.g. p.name
+	case prev.kind == punctToken && prev.text == ".":
+		if isQualifiedColumn(item) {
+			return last.text, true
+		}
+
+		return "", false
+
+	// implicit alias, e.g. p.name pet_name
+	case prev.kind == identToken || prev.kind == quotedToken || prev.kind == numberToken || prev.kind == stringToken || prev.kind == punctToken && prev.text == ")":
+		if last.kind == identToken && reserved[strings.ToUpper(last.text)] {
+			return "", false
+		}
+
+		return last.text, true
+	}
+
+	return "", false
+}
+
+// isQualifiedColumn returns true if the provided item takes the form of a (qualified) column,
+// e.g. schema.table.column.
+func isQualifiedColumn(item []token) bool {
+	for i, t := range item {
+		if i%2 == 1 {
+			if t.kind != punctToken || t.text != "." {
+				return false
+			}
+
+			continue
+		}
+
+		if t.kind != identToken && t.kind != quotedToken {
+			return false
+		}
+	}
+
+	return len(item)%2 == 1
+}
+
+// tokenize splits the provided query into tokens, skipping any whitespace and comments.
+func tokenize(query string) []token {
+	runes := []rune(query)
+	tokens := make([]token, 0)
+	depth := 0
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			i += 2
+			for i+1 < len(runes) && !(runes[i] == '*' && runes[i+1] == '/') {
+				i++
+			}
+
+			i += 2
+
+		case r == '\'' || r == '"' || r == '`' || r == '[':
+			closing := r
+			if r == '[' {
+				closing = ']'
+			}
+
+			text, next := readQuoted(runes, i+1, closing)
+
+			kind := quotedToken
+			if r == '\'' {
+				kind = stringToken
+			}
+
+			tokens = append(tokens, token{text: text, kind: kind, depth: depth})
+			i = next
+
+		case isIdentStart(r):
+			start := i
+			for i < len(runes) && isIdentPart(runes[i]) {
+				i++
+			}
+
+			tokens = append(tokens, token{text: string(runes[start:i]), kind: identToken, depth: depth})
+
+		case unicode.IsDigit(r):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+
+			tokens = append(tokens, token{text: string(runes[start:i]), kind: numberToken, depth: depth})
+
+		default:
+			if r == ')' {
+				depth--
+			}
+
+			tokens = append(tokens, token{text: string(r), kind: punctToken, depth: depth})
+
+			if r == '(' {
+				depth++
+			}
+
+			i++
+		}
+	}
+
+	return tokens
+}
+
+// readQuoted reads a quoted string (starting at i) up to the provided closing quote, where a
+// repeated closing quote is an escaped quote. The text and the position after the closing quote
+// are returned.
+func readQuoted(runes []rune, i int, closing rune) (string, int) {
+	b := strings.Builder{}
+
+	for i < len(runes) {
+		if runes[i] != closing {
+			b.WriteRune(runes[i])
+			i++
+			continue
+		}
+
+		if i+1 < len(runes) && runes[i+1] == closing && closing != ']' {
+			b.WriteRune(closing)
+			i += 2
+			continue
+		}
+
+		return b.String(), i + 1
+	}
+
+	return b.String(), i
+}
+
+func isIdentStart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
+
+func isIdentPart(r rune) bool {
+	return r == '_' || r == '$' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
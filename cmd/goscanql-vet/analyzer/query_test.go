@@ -0,0 +1,51 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSelect(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		expected   selectList
+		expectedOk bool
+	}{
+		{
+			name:       "GivenColumnsAndAliases_ThenNamesReturned",
+			query:      `SELECT DISTINCT u.id, "u"."Name", COUNT(*) AS total, p.name pet_name, CAST(p.age AS int) AS pets_age FROM users u`,
+			expected:   selectList{aliases: []string{"id", "Name", "total", "pet_name", "pets_age"}, complete: true},
+			expectedOk: true,
+		},
+		{
+			name:       "GivenWildcardsAndUnnamedExpressions_ThenIncomplete",
+			query:      "SELECT p.*, name, 'literal', CASE WHEN a THEN 1 END, x::text FROM pets p",
+			expected:   selectList{aliases: []string{"name"}, complete: false},
+			expectedOk: true,
+		},
+		{
+			name:       "GivenCommonTableExpressionAndComments_ThenOuterSelectParsed",
+			query:      "WITH p AS (SELECT id, name FROM pets) -- pets, with names\nSELECT /* the id */ id AS `pet_id`, name FROM p ORDER BY id",
+			expected:   selectList{aliases: []string{"pet_id", "name"}, complete: true},
+			expectedOk: true,
+		},
+		{
+			name:       "GivenNoSelect_ThenNotOk",
+			query:      "UPDATE pets SET name = 'Babou'",
+			expectedOk: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Act
+			actual, ok := parseSelect(test.query)
+
+			// Assert
+			assert.Equal(t, test.expectedOk, ok)
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
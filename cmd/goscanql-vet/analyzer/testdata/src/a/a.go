@@ -0,0 +1,150 @@
+package a
+
+import (
+	"context"
+	"database/sql"
+	"net"
+	"time"
+
+	"github.com/rustedturnip/goscanql"
+)
+
+type Colour struct {
+	Red   int `sql:"red"`
+	Green int `sql:"green"`
+}
+
+type Pet struct {
+	Name   string  `sql:"name"`
+	Animal string  `sql:"animal"`
+	Colour *Colour `sql:"colour"`
+}
+
+type User struct {
+	ID       int                 `sql:"id"`
+	Nickname goscanql.NullString `sql:"nickname"`
+	Joined   time.Time           `sql:"joined"`
+	Pets     []Pet               `sql:"pets"`
+	Aliases  []string            `sql:"alias"`
+	Ignored  string
+}
+
+const userQuery = `
+SELECT u.id, u.nickname, u.joined,
+       p.name AS pets_name, p.animal AS pets_animal, p.red AS pets_colour_red, p.green pets_colour_green,
+       a.alias
+FROM users u
+LEFT JOIN pets p ON p.user_id = u.id
+LEFT JOIN aliases a ON a.user_id = u.id`
+
+func matching(db *sql.DB) ([]*User, error) {
+	rows, err := db.Query(userQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	return goscanql.RowsToStructs[*User](rows)
+}
+
+func typo(db *sql.DB) ([]*User, error) {
+	rows, err := db.Query("SELECT id, nickname, joined, alias, p.name AS pets_name, p.animal AS pets_anmial, " + // want `column "pets_anmial" does not map to a field of User \(did you mean "pets_animal"\?\)`
+		"p.red AS pets_colour_red, p.green AS pets_colour_green FROM users JOIN pets p")
+	if err != nil {
+		return nil, err
+	}
+
+	return goscanql.RowsToStructs[*User](rows)
+}
+
+func unknownAndMissing(ctx context.Context, db *sql.DB) (User, error) {
+	query := "SELECT id, nickname, COUNT(*) AS total, species AS pets_species FROM users" // want `column "total" does not map to a field of User` `column "pets_species" does not map to a field of User \(Pet has no field tagged "species"\)`
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return User{}, err
+	}
+
+	return goscanql.RowsToStruct[User](rows) // want `field User.Joined \(column "joined"\) is not selected by the query` `field Pet.Name \(column "pets_name"\) is not selected by the query` `field Pet.Animal \(column "pets_animal"\) is not selected by the query` `field Colour.Red \(column "pets_colour_red"\) is not selected by the query` `field Colour.Green \(column "pets_colour_green"\) is not selected by the query` `field User.Aliases \(column "alias"\) is not selected by the query`
+}
+
+func wildcard(db *sql.DB) ([]*Pet, error) {
+	rows, err := db.Query("SELECT *, animal AS anmial FROM pets") // want `column "anmial" does not map to a field of Pet \(did you mean "animal"\?\)`
+	if err != nil {
+		return nil, err
+	}
+
+	return goscanql.RowsToStructs[*Pet](rows)
+}
+
+func withOptions(db *sql.DB) ([]*Pet, error) {
+	rows, err := db.Query("SELECT NAME FROM pets")
+	if err != nil {
+		return nil, err
+	}
+
+	return goscanql.RowsToStructs[*Pet](rows, goscanql.WithCaseInsensitiveColumns())
+}
+
+func dynamic(db *sql.DB, query string) ([]*Pet, error) {
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return goscanql.RowsToStructs[*Pet](rows)
+}
+
+// Money is decoded from its text form (e.g. "12.50 GBP"), so is a single column.
+type Money struct {
+	Pence    int64
+	Currency string
+}
+
+func (m *Money) UnmarshalText(text []byte) error {
+	return nil
+}
+
+type Payment struct {
+	ID     int      `sql:"id"`
+	Amount Money    `sql:"amount"`
+	Refund *Money   `sql:"refund"`
+	IPs    []net.IP `sql:"ip"`
+}
+
+func unmarshalers(db *sql.DB) ([]*Payment, error) {
+	rows, err := db.Query("SELECT id, amount, refund, ip, amount_pence FROM payments") // want `column "amount_pence" does not map to a field of Payment`
+	if err != nil {
+		return nil, err
+	}
+
+	return goscanql.RowsToStructs[*Payment](rows)
+}
+
+type Report struct {
+	ID    int            `sql:"id"`
+	Extra map[string]any `sql:",extra"`
+	Pets  []Pet          `sql:"pets"`
+}
+
+func extra(db *sql.DB) ([]*Report, error) {
+	rows, err := db.Query("SELECT id, COUNT(*) AS total, pets_name, pets_animal, pets_colour_red, pets_colour_green, pets_weight FROM users") // want `column "pets_weight" does not map to a field of Report \(Pet has no field tagged "weight"\)`
+	if err != nil {
+		return nil, err
+	}
+
+	return goscanql.RowsToStructs[*Report](rows)
+}
+
+type Ticket struct {
+	ID   int    `sql:"id"`
+	Note string `sql:"note,default='a,extra,b'"`
+}
+
+func quotedOption(db *sql.DB) ([]*Ticket, error) {
+	rows, err := db.Query("SELECT id, note, total FROM tickets") // want `column "total" does not map to a field of Ticket`
+	if err != nil {
+		return nil, err
+	}
+
+	return goscanql.RowsToStructs[*Ticket](rows)
+}
@@ -0,0 +1,24 @@
+// Package goscanql is a stub of goscanql, holding the declarations that the analyzer relies on.
+package goscanql
+
+import "database/sql"
+
+type Option func()
+
+type NullString struct {
+	String string
+	Valid  bool
+}
+
+func (ns *NullString) Scan(value interface{}) error { return nil }
+
+func (ns *NullString) ID() []byte { return nil }
+
+func RowsToStructs[T any](rows *sql.Rows, opts ...Option) ([]T, error) { return nil, nil }
+
+func RowsToStruct[T any](rows *sql.Rows, opts ...Option) (T, error) {
+	var zero T
+	return zero, nil
+}
+
+func WithCaseInsensitiveColumns() Option { return nil }
@@ -0,0 +1,16 @@
+module github.com/rustedturnip/goscanql/cmd/goscanql-vet
+
+go 1.25.0
+
+require (
+	github.com/stretchr/testify v1.8.2
+	golang.org/x/tools v0.47.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/mod v0.37.0 // indirect
+	golang.org/x/sync v0.21.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
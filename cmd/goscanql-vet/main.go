@@ -0,0 +1,26 @@
+// Command goscanql-vet checks the queries scanned by goscanql against the sql tags of the types
+// that they are scanned into. Where a call of RowsToStructs (or RowsToStruct) scans rows that are
+// produced by a constant query, the columns selected by the query are compared to the columns of
+// the type, and any of the following are reported:
+//
+//   - columns that map to no field (including typos, e.g. pets_anmial rather than pets_animal)
+//   - fields that no column maps to
+//
+// It can be run on its own, e.g.
+//
+//	goscanql-vet ./...
+//
+// or with go vet:
+//
+//	go vet -vettool=$(which goscanql-vet) ./...
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/singlechecker"
+
+	"github.com/rustedturnip/goscanql/cmd/goscanql-vet/analyzer"
+)
+
+func main() {
+	singlechecker.Main(analyzer.Analyzer)
+}
@@ -0,0 +1,597 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// printf writes the formatted string to the generated code.
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// writeHeader writes the header of the generated file, including each of the imports that are
+// referenced by the provided body of the generated code.
+func (g *generator) writeHeader(body string) {
+	g.printf("// Code generated by goscanqlgen. DO NOT EDIT.\n\n")
+	g.printf("package %s\n\n", g.pkg.Name())
+
+	paths := make([]string, 0, len(g.imports))
+	for p, name := range g.imports {
+		if strings.Contains(body, name+".") {
+			paths = append(paths, p)
+		}
+	}
+
+	sort.Slice(paths, func(i, j int) bool {
+		if isStandard(paths[i]) != isStandard(paths[j]) {
+			return isStandard(paths[i])
+		}
+
+		return paths[i] < paths[j]
+	})
+
+	g.printf("import (\n")
+
+	for i, p := range paths {
+		// the standard library is grouped ahead of any other packages
+		if i > 0 && isStandard(paths[i-1]) && !isStandard(p) {
+			g.printf("\n")
+		}
+
+		if g.imports[p] != path.Base(p) {
+			g.printf("%s %q\n", g.imports[p], p)
+			continue
+		}
+
+		g.printf("%q\n", p)
+	}
+
+	g.printf(")\n")
+}
+
+// isStandard returns true if the provided import path belongs to the standard library.
+func isStandard(p string) bool {
+	return !strings.Contains(strings.Split(p, "/")[0], ".")
+}
+
+// root holds the names of the generated declarations of a single root type.
+type root struct {
+
+	// name is the name of the root type.
+	name string
+
+	// row is the name of the type that holds the entities of a single row.
+	row string
+
+	// parents maps each node to the edge from its parent (nil for the root).
+	parents map[*node]*edge
+
+	// parentNodes maps each node to its parent node (nil for the root).
+	parentNodes map[*node]*node
+}
+
+// newRoot creates a new root for the named root type, from the nodes of its tree.
+func newRoot(name string, nodes []*node) *root {
+	r := &root{
+		name:        name,
+		row:         "goscanqlgen" + name + "Row",
+		parents:     map[*node]*edge{},
+		parentNodes: map[*node]*node{},
+	}
+
+	for _, n := range nodes {
+		for _, e := range n.children {
+			r.parents[e.child] = e
+			r.parentNodes[e.child] = n
+		}
+	}
+
+	return r
+}
+
+// writeRoot writes the scan function of the named root type, along with the declarations that it
+// relies on.
+func (g *generator) writeRoot(name string, n *node) {
+	r := newRoot(name, g.nodes)
+
+	quoted := make([]string, len(g.columns))
+	for i, column := range g.columns {
+		quoted[i] = fmt.Sprintf("%q", column)
+	}
+
+	g.printf("\n// goscanqlgen%sColumns are the names of the columns that are mapped to %s.\n", name, name)
+	g.printf("var goscanqlgen%sColumns = []string{%s}\n", name, strings.Join(quoted, ", "))
+
+	g.writeScan(r, n)
+	g.writeRow(r)
+	g.writeScanRow(r)
+
+	for _, k := range g.nodes {
+		g.writeKey(r, k)
+	}
+
+	for _, k := range g.nodes {
+		if k.oneToOne {
+			continue
+		}
+
+		g.writeMerge(r, k)
+	}
+
+	for _, k := range g.nodes {
+		if k.value == nil && k.needsFinalize() {
+			g.writeFinalize(r, k)
+		}
+	}
+}
+
+// writeScan writes the exported scan function of the root type.
+func (g *generator) writeScan(r *root, n *node) {
+	g.printf("\n// Scan%s scans the provided rows into a slice of *%s. It behaves in the same way as\n", r.name, r.name)
+	g.printf("// goscanql.RowsToStructs[*%s] (with the default options), but doesn't use reflection.\n", r.name)
+	g.printf("func Scan%s(rows *sql.Rows) ([]*%s, error) {\n", r.name, r.name)
+	g.printf("cols, err := rows.Columns()\nif err != nil {\nreturn nil, err\n}\n\n")
+	g.printf("targets := goscanqlgenTargets(cols, goscanqlgen%sColumns)\n", r.name)
+	g.printf("entities := make([]*%s, 0)\nrecords := goscanqlgenRecords{}\n\n", r.name)
+	g.printf("for row := 1; rows.Next(); row++ {\n")
+	g.printf("r, err := goscanqlgenScan%sRow(rows.Scan, targets)\nif err != nil {\nreturn nil, err\n}\n\n", r.name)
+	g.printf("if r == nil {\ncontinue\n}\n\n")
+	g.printf("err = r.merge%d(&entities, records, row)\nif err != nil {\nreturn nil, err\n}\n}\n\n", n.index)
+
+	if n.needsFinalize() {
+		g.printf("for _, e := range entities {\n")
+		g.printf("err := goscanqlgenFinalize%s%d(e)\nif err != nil {\nreturn nil, err\n}\n}\n\n", r.name, n.index)
+	}
+
+	g.printf("return entities, nil\n}\n")
+}
+
+// writeRow writes the type that holds the entities of a single row.
+func (g *generator) writeRow(r *root) {
+	g.printf("\n// %s holds the entities of a single row scanned by Scan%s, and whether each of\n", r.row, r.name)
+	g.printf("// them is live (i.e. it, and each of its parents, isn't nil).\n")
+	g.printf("type %s struct {\n", r.row)
+
+	for _, n := range g.nodes {
+		g.printf("n%d *%s\n", n.index, n.typeExpr)
+	}
+
+	g.printf("\n")
+
+	for _, n := range g.nodes {
+		g.printf("live%d bool\n", n.index)
+	}
+
+	g.printf("}\n")
+}
+
+// writeScanRow writes the function that scans a single row into a new row type.
+func (g *generator) writeScanRow(r *root) {
+	g.printf("\n// goscanqlgenScan%sRow scans the current row into a new %s, returning nil if the\n", r.name, r.row)
+	g.printf("// row doesn't hold a %s.\n", r.name)
+	g.printf("func goscanqlgenScan%sRow(scan func(...interface{}) error, targets []int) (*%s, error) {\n", r.name, r.row)
+	g.printf("var discard interface{}\n\n")
+	g.printf("nulls := make([]bool, %d)\n\n", len(g.columns))
+	g.printf("err := scan(goscanqlgenNullDest(targets, nulls, &discard)...)\nif err != nil {\nreturn nil, err\n}\n\n")
+	g.printf("r := &%s{}\n", r.row)
+
+	// an entity is nil where all of its own values are null
+	for _, n := range g.nodes {
+		nulls := make([]string, 0, len(n.leaves)+1)
+
+		for _, l := range n.allLeaves() {
+			nulls = append(nulls, fmt.Sprintf("nulls[%d]", l.column))
+		}
+
+		live := "false"
+		if len(nulls) > 0 {
+			live = fmt.Sprintf("!(%s)", strings.Join(nulls, " && "))
+		}
+
+		if parent := r.parentNodes[n]; parent != nil {
+			live = fmt.Sprintf("r.live%d && %s", parent.index, live)
+		}
+
+		g.printf("r.live%d = %s\n", n.index, live)
+
+		if n.index == 0 {
+			g.printf("\nif !r.live0 {\nreturn nil, nil\n}\n\n")
+		}
+	}
+
+	g.printf("\n")
+
+	for _, n := range g.nodes {
+		e := r.parents[n]
+
+		switch {
+		case e == nil || e.many:
+			g.printf("r.n%d = new(%s)\n", n.index, n.typeExpr)
+		case e.pointer:
+			g.printf("r.n%d.%s = new(%s)\n", r.parentNodes[n].index, e.field, n.typeExpr)
+			g.printf("r.n%d = r.n%d.%s\n", n.index, r.parentNodes[n].index, e.field)
+		default:
+			g.printf("r.n%d = &r.n%d.%s\n", n.index, r.parentNodes[n].index, e.field)
+		}
+
+		for _, l := range n.leaves {
+			if l.pointer {
+				g.printf("r.n%d.%s = new(%s)\n", n.index, l.field, l.elemExpr)
+			}
+		}
+	}
+
+	g.printf("\nrefs := make([]interface{}, %d)\n", len(g.columns))
+
+	for _, n := range g.nodes {
+		leaves := n.allLeaves()
+		if len(leaves) == 0 {
+			continue
+		}
+
+		g.printf("\nif r.live%d {\n", n.index)
+
+		for _, l := range leaves {
+			g.printf("refs[%d] = %s\n", l.column, n.reference(l))
+		}
+
+		g.printf("}\n")
+	}
+
+	g.printf("\nerr = scan(goscanqlgenDest(targets, refs, &discard)...)\nif err != nil {\nreturn nil, err\n}\n")
+
+	// nil one-to-one children are emptied, as goscanql would
+	for _, n := range g.nodes {
+		e := r.parents[n]
+		if e == nil || e.many {
+			continue
+		}
+
+		parent := r.parentNodes[n]
+
+		g.printf("\nif r.live%d && !r.live%d {\n", parent.index, n.index)
+
+		if e.pointer {
+			g.printf("r.n%d.%s = nil\n", parent.index, e.field)
+		} else {
+			g.printf("*r.n%d = %s{}\n", n.index, n.typeExpr)
+		}
+
+		g.printf("}\n")
+	}
+
+	for _, n := range g.nodes {
+		if !n.hooks["AfterScan"] {
+			continue
+		}
+
+		g.printf("\nif r.live%d {\n", n.index)
+		g.writeHook(fmt.Sprintf("r.n%d", n.index), "AfterScan", n, "nil, ")
+		g.printf("}\n")
+	}
+
+	g.printf("\nreturn r, nil\n}\n")
+}
+
+// writeHook writes the call of the named hook on the provided entity (of node n), returning the
+// provided results (followed by the error) if the hook fails.
+func (g *generator) writeHook(entity, hook string, n *node, results string) {
+	g.imports["fmt"] = "fmt"
+
+	g.printf("if err := %s.%s(); err != nil {\n", entity, hook)
+	g.printf("return %sfmt.Errorf(\"goscanql: %s failed for %s: %%w\", err)\n}\n", results, hook, n.hookType)
+}
+
+// writeKey writes the function that appends the key of the provided node to a slice of bytes,
+// which is equal for two entities where goscanql would find their hashes to be equal.
+func (g *generator) writeKey(r *root, n *node) {
+	g.printf("\nfunc (r *%s) key%d(b []byte) []byte {\n", r.row, n.index)
+
+	for _, l := range n.allLeaves() {
+		g.writeLeafKey(n, l)
+	}
+
+	for _, e := range n.children {
+		if !e.many {
+			g.printf("b = r.key%d(b)\n", e.child.index)
+		}
+	}
+
+	g.printf("return b\n}\n")
+}
+
+// writeLeafKey writes the code that appends the key of the provided leaf (of node n) to b.
+func (g *generator) writeLeafKey(n *node, l *leaf) {
+	value, receiver := n.valueOf(l), n.receiverOf(l)
+
+	switch {
+	case l.scanner && l.pointer:
+		g.printf("if %s != nil {\n", receiver)
+		g.printf("b = goscanqlgenAppendString(b, string(%s.ID()))\n", receiver)
+		g.printf("} else {\n")
+		g.printf("b = goscanqlgenAppendString(b, string(new(%s).ID()))\n}\n", l.elemExpr)
+	case l.scanner:
+		g.printf("b = goscanqlgenAppendString(b, string(%s.ID()))\n", receiver)
+	case l.pointer && l.hash == hashTime:
+		// goscanql identifies times by their instant through pointers too (and nil with %#v)
+		g.imports["fmt"] = "fmt"
+		g.imports["time"] = "time"
+		g.printf("if t := %s; t != nil {\n", value)
+		g.printf("b = goscanqlgenAppendString(b, t.UTC().Format(time.RFC3339Nano))\n")
+		g.printf("} else {\n")
+		g.printf("b = goscanqlgenAppendString(b, fmt.Sprintf(\"%%#v\", t))\n}\n")
+	case l.pointer || l.hash == hashOther:
+		// goscanql formats values with %#v, so pointers are identified by their address
+		g.imports["fmt"] = "fmt"
+		g.printf("b = goscanqlgenAppendString(b, fmt.Sprintf(\"%%#v\", %s))\n", value)
+	case l.hash == hashTime:
+		// goscanql identifies times by their instant, regardless of their location
+		g.imports["time"] = "time"
+		g.printf("b = goscanqlgenAppendString(b, %s.UTC().Format(time.RFC3339Nano))\n", receiver)
+	default:
+		if l.castExpr != l.elemExpr {
+			value = fmt.Sprintf("%s(%s)", l.castExpr, value)
+		}
+
+		g.printf("b = goscanqlgenAppend%s(b, %s)\n", appendFuncs[l.hash], value)
+	}
+}
+
+// appendFuncs maps each kind of hash to the suffix of the helper that appends it to a key.
+var appendFuncs = map[hashKind]string{
+	hashInt:    "Int",
+	hashUint:   "Uint",
+	hashFloat:  "Float",
+	hashBool:   "Bool",
+	hashString: "String",
+}
+
+// writeMerge writes the function that merges the provided node (a root or one-to-many child)
+// into a slice of existing entities.
+func (g *generator) writeMerge(r *root, n *node) {
+	elem, entity := n.typeExpr, "&(*entities)[record.index]"
+	appended := fmt.Sprintf("*r.n%d", n.index)
+
+	if e := r.parents[n]; e == nil || e.pointer {
+		elem, entity = "*"+n.typeExpr, "(*entities)[record.index]"
+		appended = fmt.Sprintf("r.n%d", n.index)
+	}
+
+	children := n.oneToManys()
+
+	g.printf("\nfunc (r *%s) merge%d(entities *[]%s, records goscanqlgenRecords, row int) error {\n", r.row, n.index, elem)
+	g.printf("if !r.live%d {\nreturn nil\n}\n\n", n.index)
+
+	if n.multiset {
+		g.printf("key := goscanqlgenMultisetKey(r.key%d(nil), row)\n", n.index)
+	} else {
+		g.printf("key := string(r.key%d(nil))\n", n.index)
+	}
+
+	if len(children) == 0 && !n.hooks["AfterMerge"] {
+		g.printf("\nif _, ok := records[key]; !ok {\n")
+		g.printf("records[key] = goscanqlgenNewRecord(len(records), 0)\n")
+		g.printf("*entities = append(*entities, %s)\n}\n\nreturn nil\n}\n", appended)
+		return
+	}
+
+	g.printf("record, ok := records[key]\n\nif !ok {\n")
+	g.printf("record = goscanqlgenNewRecord(len(records), %d)\n", len(children))
+	g.printf("records[key] = record\n*entities = append(*entities, %s)\n}\n", appended)
+
+	g.printf("\ne := %s\n", entity)
+
+	for i, e := range children {
+		g.printf("\nif err := r.merge%d(&e.%s, record.children[%d], row); err != nil {\nreturn err\n}\n", e.child.index, e.field, i)
+	}
+
+	if n.hooks["AfterMerge"] {
+		g.printf("\nif ok {\n")
+		g.writeHook("e", "AfterMerge", n, "")
+		g.printf("}\n")
+	}
+
+	g.printf("\nreturn nil\n}\n")
+}
+
+// writeFinalize writes the function that calls Finalize and Validate on an entity of the provided
+// node and each of its children (children first).
+func (g *generator) writeFinalize(r *root, n *node) {
+	g.printf("\nfunc goscanqlgenFinalize%s%d(e *%s) error {\n", r.name, n.index, n.typeExpr)
+
+	for _, e := range n.children {
+		if !e.child.needsFinalize() {
+			continue
+		}
+
+		call := fmt.Sprintf("goscanqlgenFinalize%s%d", r.name, e.child.index)
+
+		switch {
+		case e.many && e.pointer:
+			g.printf("for i := range e.%s {\nif e.%s[i] == nil {\ncontinue\n}\n\n", e.field, e.field)
+			g.printf("if err := %s(e.%s[i]); err != nil {\nreturn err\n}\n}\n\n", call, e.field)
+		case e.many:
+			g.printf("for i := range e.%s {\n", e.field)
+			g.printf("if err := %s(&e.%s[i]); err != nil {\nreturn err\n}\n}\n\n", call, e.field)
+		case e.pointer:
+			g.printf("if e.%s != nil {\n", e.field)
+			g.printf("if err := %s(e.%s); err != nil {\nreturn err\n}\n}\n\n", call, e.field)
+		default:
+			g.printf("if err := %s(&e.%s); err != nil {\nreturn err\n}\n\n", call, e.field)
+		}
+	}
+
+	for _, hook := range []string{"Finalize", "Validate"} {
+		if n.hooks[hook] {
+			g.writeHook("e", hook, n, "")
+			g.printf("\n")
+		}
+	}
+
+	g.printf("return nil\n}\n")
+}
+
+// allLeaves returns each of the leaves of the node, including the leaf of a value node.
+func (n *node) allLeaves() []*leaf {
+	if n.value != nil {
+		return []*leaf{n.value}
+	}
+
+	return n.leaves
+}
+
+// valueOf returns the expression of the value of the provided leaf of the node.
+func (n *node) valueOf(l *leaf) string {
+	if l == n.value {
+		return fmt.Sprintf("*r.n%d", n.index)
+	}
+
+	return fmt.Sprintf("r.n%d.%s", n.index, l.field)
+}
+
+// receiverOf returns the expression that the methods of the provided leaf of the node are called
+// on.
+func (n *node) receiverOf(l *leaf) string {
+	if l == n.value {
+		return fmt.Sprintf("r.n%d", n.index)
+	}
+
+	return n.valueOf(l)
+}
+
+// reference returns the expression that the provided leaf of the node is scanned into.
+func (n *node) reference(l *leaf) string {
+	switch {
+	case l == n.value:
+		return fmt.Sprintf("r.n%d", n.index)
+	case l.scanner && l.pointer:
+		return n.valueOf(l)
+	}
+
+	return "&" + n.valueOf(l)
+}
+
+// writeHelpers writes the declarations that are shared by the scan functions of each root type.
+func (g *generator) writeHelpers() {
+	g.buf.WriteString(helpers)
+}
+
+// helpers are the declarations that are shared by the scan functions of each root type.
+const helpers = `
+// goscanqlgenNull records whether the value of a column is null.
+type goscanqlgenNull bool
+
+func (n *goscanqlgenNull) Scan(value interface{}) error {
+	*n = value == nil
+	return nil
+}
+
+// goscanqlgenRecords maps the key of each entity of a slice to its record.
+type goscanqlgenRecords map[string]*goscanqlgenRecord
+
+// goscanqlgenRecord records the position of an entity in its slice, and the records of each of
+// its one-to-many children.
+type goscanqlgenRecord struct {
+	index    int
+	children []goscanqlgenRecords
+}
+
+func goscanqlgenNewRecord(index, children int) *goscanqlgenRecord {
+	r := &goscanqlgenRecord{
+		index:    index,
+		children: make([]goscanqlgenRecords, children),
+	}
+
+	for i := range r.children {
+		r.children[i] = goscanqlgenRecords{}
+	}
+
+	return r
+}
+
+// goscanqlgenTargets returns the index of each of the provided columns (cols) within the mapped
+// columns, or -1 where a column isn't mapped.
+func goscanqlgenTargets(cols, columns []string) []int {
+	targets := make([]int, len(cols))
+
+	for i, col := range cols {
+		targets[i] = -1
+
+		for j, column := range columns {
+			if col == column {
+				targets[i] = j
+				break
+			}
+		}
+	}
+
+	return targets
+}
+
+// goscanqlgenNullDest returns the destinations that record whether each mapped column is null.
+func goscanqlgenNullDest(targets []int, nulls []bool, discard *interface{}) []interface{} {
+	for i := range nulls {
+		nulls[i] = true
+	}
+
+	dest := make([]interface{}, len(targets))
+
+	for i, target := range targets {
+		if target < 0 {
+			dest[i] = discard
+			continue
+		}
+
+		dest[i] = (*goscanqlgenNull)(&nulls[target])
+	}
+
+	return dest
+}
+
+// goscanqlgenDest returns the destinations of each of the columns, discarding any column that
+// isn't mapped (or belongs to an entity that isn't live).
+func goscanqlgenDest(targets []int, refs []interface{}, discard *interface{}) []interface{} {
+	dest := make([]interface{}, len(targets))
+
+	for i, target := range targets {
+		if target < 0 || refs[target] == nil {
+			dest[i] = discard
+			continue
+		}
+
+		dest[i] = refs[target]
+	}
+
+	return dest
+}
+
+func goscanqlgenMultisetKey(b []byte, row int) string {
+	b = append(b, '#')
+	return string(strconv.AppendInt(b, int64(row), 10))
+}
+
+func goscanqlgenAppendString(b []byte, s string) []byte {
+	b = strconv.AppendInt(b, int64(len(s)), 10)
+	b = append(b, ':')
+	return append(b, s...)
+}
+
+func goscanqlgenAppendInt(b []byte, i int64) []byte {
+	return append(strconv.AppendInt(b, i, 10), ';')
+}
+
+func goscanqlgenAppendUint(b []byte, u uint64) []byte {
+	return append(strconv.AppendUint(b, u, 10), ';')
+}
+
+func goscanqlgenAppendFloat(b []byte, f float64) []byte {
+	return append(strconv.AppendFloat(b, f, 'g', -1, 64), ';')
+}
+
+func goscanqlgenAppendBool(b []byte, v bool) []byte {
+	return append(strconv.AppendBool(b, v), ';')
+}
+`
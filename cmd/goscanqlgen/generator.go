@@ -0,0 +1,704 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const (
+	scanqlTag = "sql"
+
+	goscanqlPath = "github.com/rustedturnip/goscanql"
+
+	// multisetTagOption is the tag option of goscanql that is understood by the generator
+	multisetTagOption = "multiset"
+)
+
+// unsupportedTagOptions are the tag options of goscanql that the generator doesn't support, either
+// because they rely on reflection at runtime (orderby), because the generated code doesn't yet
+// implement them (notnull, default and extra), or because goscanql rejects them in sql tags (key).
+var unsupportedTagOptions = []string{"orderby", "notnull", "default", "extra", "key"}
+
+// hashKind represents the way in which a value is appended to the hash of an entity.
+type hashKind int
+
+const (
+	hashInt hashKind = iota
+	hashUint
+	hashFloat
+	hashBool
+	hashString
+	hashTime
+	hashOther
+)
+
+// node represents a single entity of the tree of a root type, i.e. the root itself, a one-to-one
+// child or a one-to-many child. Nodes are generated per path (rather than per type) as the
+// columns of a type depend on where it appears.
+type node struct {
+
+	// index is the position of the node in the tree (in depth first order), which is used to name
+	// its variables and functions.
+	index int
+
+	// typeExpr is the expression of the type of the node, i.e. the struct type of an entity, or the
+	// type of the value of a value node.
+	typeExpr string
+
+	// hookType is the name of the type of the node, as it would be formatted by reflect (and so by
+	// goscanql) in the error of a failed hook, e.g. models.User.
+	hookType string
+
+	// leaves are the values (and Scanners) of the node.
+	leaves []*leaf
+
+	// children are the one-to-one and one-to-many children of the node, in the order that they are
+	// declared.
+	children []*edge
+
+	// value is the leaf of a node that is an element of a slice of values (or Scanners), e.g.
+	// []string.
+	value *leaf
+
+	// oneToOne determines whether the node is the child of a one-to-one relationship.
+	oneToOne bool
+
+	// multiset determines whether duplicate entities are preserved (for one-to-many children).
+	multiset bool
+
+	// hooks holds the names of the goscanql hooks that the type implements.
+	hooks map[string]bool
+}
+
+// oneToManys returns the one-to-many children of the node.
+func (n *node) oneToManys() []*edge {
+	edges := make([]*edge, 0, len(n.children))
+
+	for _, e := range n.children {
+		if e.many {
+			edges = append(edges, e)
+		}
+	}
+
+	return edges
+}
+
+// needsFinalize returns true if the node (or any of its children) implements Finalize or Validate.
+func (n *node) needsFinalize() bool {
+	if n.hooks["Finalize"] || n.hooks["Validate"] {
+		return true
+	}
+
+	for _, e := range n.children {
+		if e.child.needsFinalize() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// leaf represents a single value (or Scanner) of a node.
+type leaf struct {
+
+	// field is the name of the struct field (empty for the leaf of a value node).
+	field string
+
+	// column is the index of the leaf's column in the generated column list.
+	column int
+
+	// scanner determines whether the leaf implements goscanql.Scanner.
+	scanner bool
+
+	// pointer determines whether the field is a pointer (e.g. *int).
+	pointer bool
+
+	// elemExpr is the expression of the type of the value (without the pointer).
+	elemExpr string
+
+	// hash is the way in which the value is hashed (for non-Scanners).
+	hash hashKind
+
+	// castExpr is the type that the value must be converted to before it is hashed (e.g. int64).
+	castExpr string
+}
+
+// edge represents the relationship between a node and one of its children.
+type edge struct {
+
+	// field is the name of the struct field of the relationship.
+	field string
+
+	// pointer determines whether the child is held via a pointer (e.g. *Colour, or []*Pet).
+	pointer bool
+
+	// many determines whether the relationship is one-to-many (rather than one-to-one).
+	many bool
+
+	// child is the node of the child.
+	child *node
+}
+
+// generator builds the tree of each of the root types, and writes the generated code.
+type generator struct {
+	pkg *types.Package
+
+	// imports maps the path of each package referenced by the generated code to its name.
+	imports map[string]string
+
+	// columns are the names of the columns of the root type currently being generated.
+	columns []string
+
+	// nodes are each of the nodes of the root type currently being generated.
+	nodes []*node
+
+	buf strings.Builder
+}
+
+// Generate generates the scan functions for the provided types (or every tagged struct type where
+// none are provided) of the package in the provided directory, returning the source of the
+// generated file. The file with the provided output name is ignored when loading the package, so
+// that a stale generated file doesn't prevent regeneration.
+func Generate(dir string, typeNames []string, output string) ([]byte, error) {
+	pkg, err := loadPackage(dir, output)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(typeNames) == 0 {
+		typeNames = taggedStructs(pkg)
+	}
+
+	if len(typeNames) == 0 {
+		return nil, fmt.Errorf("no tagged structs found in %s", dir)
+	}
+
+	g := &generator{
+		pkg:     pkg,
+		imports: map[string]string{"database/sql": "sql", "strconv": "strconv"},
+	}
+
+	body := &strings.Builder{}
+
+	for _, name := range typeNames {
+		obj := pkg.Scope().Lookup(name)
+		if obj == nil {
+			return nil, fmt.Errorf("type %s not found", name)
+		}
+
+		named, ok := obj.Type().(*types.Named)
+		if !ok || !isStruct(named) {
+			return nil, fmt.Errorf("type %s is not a struct", name)
+		}
+
+		g.columns = nil
+		g.nodes = nil
+
+		root, err := g.buildNode(named, "", false, map[types.Type]bool{})
+		if err != nil {
+			return nil, err
+		}
+
+		err = g.checkColumns(name)
+		if err != nil {
+			return nil, err
+		}
+
+		g.buf.Reset()
+		g.writeRoot(name, root)
+		body.WriteString(g.buf.String())
+	}
+
+	g.buf.Reset()
+	g.writeHelpers()
+	body.WriteString(g.buf.String())
+
+	g.buf.Reset()
+	g.writeHeader(body.String())
+	g.buf.WriteString(body.String())
+
+	return format.Source([]byte(g.buf.String()))
+}
+
+// loadPackage parses and type checks the package in the provided directory (ignoring test files
+// and the file with the provided output name).
+func loadPackage(dir, output string) (*types.Package, error) {
+	fset := token.NewFileSet()
+
+	filter := func(fi fs.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go") && fi.Name() != output
+	}
+
+	pkgs, err := parser.ParseDir(fset, dir, filter, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("expected a single package in %s, found %d", dir, len(pkgs))
+	}
+
+	var files []*ast.File
+	var name string
+
+	for pkgName, pkg := range pkgs {
+		name = pkgName
+
+		fileNames := make([]string, 0, len(pkg.Files))
+		for fileName := range pkg.Files {
+			fileNames = append(fileNames, fileName)
+		}
+
+		sort.Strings(fileNames)
+
+		for _, fileName := range fileNames {
+			files = append(files, pkg.Files[fileName])
+		}
+	}
+
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+
+	return conf.Check(name, fset, files, nil)
+}
+
+// taggedStructs returns the name of every struct type declared by the package that has at least
+// one field with an sql tag.
+func taggedStructs(pkg *types.Package) []string {
+	names := make([]string, 0)
+
+	for _, name := range pkg.Scope().Names() {
+		typeName, ok := pkg.Scope().Lookup(name).(*types.TypeName)
+		if !ok || typeName.IsAlias() {
+			continue
+		}
+
+		st, ok := typeName.Type().Underlying().(*types.Struct)
+		if !ok {
+			continue
+		}
+
+		for i := 0; i < st.NumFields(); i++ {
+			if _, ok := reflect.StructTag(st.Tag(i)).Lookup(scanqlTag); ok {
+				names = append(names, name)
+				break
+			}
+		}
+	}
+
+	return names
+}
+
+// buildNode builds the node of the provided struct type (t), whose columns have the provided
+// prefix.
+func (g *generator) buildNode(t types.Type, prefix string, oneToOne bool, seen map[types.Type]bool) (*node, error) {
+	if seen[t] {
+		return nil, fmt.Errorf("goscanql does not support cyclic structs: %s", g.typeExpr(t))
+	}
+
+	seen[t] = true
+	defer delete(seen, t)
+
+	n := &node{
+		index:    len(g.nodes),
+		typeExpr: g.typeExpr(t),
+		hookType: types.TypeString(t, func(p *types.Package) string { return p.Name() }),
+		oneToOne: oneToOne,
+		hooks:    hooksOf(t),
+	}
+
+	g.nodes = append(g.nodes, n)
+
+	st := t.Underlying().(*types.Struct)
+
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+
+		if isPresence(field.Type()) {
+			return nil, fmt.Errorf("goscanql.Presence (%s.%s) is not supported", n.typeExpr, field.Name())
+		}
+
+		raw, ok := reflect.StructTag(st.Tag(i)).Lookup(scanqlTag)
+		if !ok || raw == "-" {
+			continue
+		}
+
+		name, options := parseTag(raw)
+		for _, option := range unsupportedTagOptions {
+			if _, ok := options[option]; ok {
+				return nil, fmt.Errorf("the %s tag option of %s.%s is not supported", option, n.typeExpr, field.Name())
+			}
+		}
+
+		err := g.addField(n, field, buildReferenceName(prefix, name), options, seen)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return n, nil
+}
+
+// addField adds the provided field (with the provided column name and tag options) to the
+// provided node, as either a leaf or a child.
+func (g *generator) addField(n *node, field *types.Var, column string, options map[string]string, seen map[types.Type]bool) error {
+	t := field.Type()
+	pointer := false
+
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+		pointer = true
+	}
+
+	if _, ok := t.(*types.Pointer); ok {
+		return fmt.Errorf("field %s.%s has multiple levels of pointers, which is not supported", n.typeExpr, field.Name())
+	}
+
+	if isUnmarshaler(t) {
+		return fmt.Errorf("field %s.%s is decoded by UnmarshalText or UnmarshalBinary, which is not supported", n.typeExpr, field.Name())
+	}
+
+	switch {
+	case isScanner(t) || isTime(t) || !isStruct(t) && !isSlice(t):
+		l, err := g.newLeaf(t, column, pointer)
+		if err != nil {
+			return fmt.Errorf("field %s.%s: %w", n.typeExpr, field.Name(), err)
+		}
+
+		l.field = field.Name()
+		n.leaves = append(n.leaves, l)
+
+	case isStruct(t):
+		child, err := g.buildNode(t, column, true, seen)
+		if err != nil {
+			return err
+		}
+
+		n.children = append(n.children, &edge{field: field.Name(), pointer: pointer, child: child})
+
+	default:
+		// goscanql only merges the one-to-many children of entities that are themselves roots or
+		// one-to-many children, so these can't be generated faithfully
+		if n.oneToOne {
+			return fmt.Errorf("field %s.%s is a one-to-many relationship within a one-to-one relationship, which is not supported", n.typeExpr, field.Name())
+		}
+
+		if pointer {
+			return fmt.Errorf("field %s.%s is a pointer to a slice, which is not supported", n.typeExpr, field.Name())
+		}
+
+		elem := t.Underlying().(*types.Slice).Elem()
+		elemPointer := false
+
+		if p, ok := elem.(*types.Pointer); ok {
+			elem = p.Elem()
+			elemPointer = true
+		}
+
+		child, err := g.buildElement(n, field, elem, elemPointer, column, seen)
+		if err != nil {
+			return err
+		}
+
+		_, child.multiset = options[multisetTagOption]
+		n.children = append(n.children, &edge{field: field.Name(), pointer: elemPointer, many: true, child: child})
+	}
+
+	return nil
+}
+
+// buildElement builds the node of an element (of type elem) of the provided slice field, whose
+// columns have the provided prefix.
+func (g *generator) buildElement(n *node, field *types.Var, elem types.Type, pointer bool, prefix string, seen map[types.Type]bool) (*node, error) {
+	if _, ok := elem.(*types.Pointer); ok {
+		return nil, fmt.Errorf("field %s.%s has multiple levels of pointers, which is not supported", n.typeExpr, field.Name())
+	}
+
+	if isUnmarshaler(elem) {
+		return nil, fmt.Errorf("field %s.%s is decoded by UnmarshalText or UnmarshalBinary, which is not supported", n.typeExpr, field.Name())
+	}
+
+	if isStruct(elem) && !isScanner(elem) && !isTime(elem) {
+		return g.buildNode(elem, prefix, false, seen)
+	}
+
+	switch {
+	case isScanner(elem) && (pointer || !isStruct(elem)):
+		return nil, fmt.Errorf("field %s.%s is a slice of %s, only slices of struct Scanners are supported", n.typeExpr, field.Name(), g.typeExpr(elem))
+	case isSlice(elem) && !isScanner(elem):
+		return nil, fmt.Errorf("field %s.%s is a multidimensional slice, which is not supported", n.typeExpr, field.Name())
+	}
+
+	l, err := g.newLeaf(elem, prefix, false)
+	if err != nil {
+		return nil, fmt.Errorf("field %s.%s: %w", n.typeExpr, field.Name(), err)
+	}
+
+	child := &node{
+		index:    len(g.nodes),
+		typeExpr: l.elemExpr,
+		value:    l,
+		hooks:    map[string]bool{},
+	}
+
+	g.nodes = append(g.nodes, child)
+
+	return child, nil
+}
+
+// checkColumns returns an error if more than one field of the named root type is mapped to the
+// same column.
+func (g *generator) checkColumns(name string) error {
+	seen := make(map[string]bool, len(g.columns))
+
+	for _, column := range g.columns {
+		if seen[column] {
+			return fmt.Errorf("more than one field of %s is mapped to the column %s", name, column)
+		}
+
+		seen[column] = true
+	}
+
+	return nil
+}
+
+// newLeaf creates a new leaf of the provided type (t), mapped to the provided column.
+func (g *generator) newLeaf(t types.Type, column string, pointer bool) (*leaf, error) {
+	switch t.Underlying().(type) {
+	case *types.Map:
+		return nil, fmt.Errorf("maps are not supported (%s)", g.typeExpr(t))
+	case *types.Array:
+		if !isScanner(t) {
+			return nil, fmt.Errorf("arrays are not supported (%s)", g.typeExpr(t))
+		}
+	case *types.Chan:
+		return nil, fmt.Errorf("chans are not supported (%s)", g.typeExpr(t))
+	case *types.Signature:
+		return nil, fmt.Errorf("funcs are not supported (%s)", g.typeExpr(t))
+	}
+
+	l := &leaf{
+		column:   len(g.columns),
+		scanner:  isScanner(t),
+		pointer:  pointer,
+		elemExpr: g.typeExpr(t),
+		hash:     hashOther,
+	}
+
+	g.columns = append(g.columns, column)
+
+	if isTime(t) {
+		l.hash = hashTime
+		return l, nil
+	}
+
+	basic, ok := t.Underlying().(*types.Basic)
+	if !ok {
+		return l, nil
+	}
+
+	info := basic.Info()
+
+	switch {
+	case info&types.IsInteger != 0 && info&types.IsUnsigned != 0:
+		l.hash, l.castExpr = hashUint, "uint64"
+	case info&types.IsInteger != 0:
+		l.hash, l.castExpr = hashInt, "int64"
+	case info&types.IsFloat != 0:
+		l.hash, l.castExpr = hashFloat, "float64"
+	case info&types.IsBoolean != 0:
+		l.hash, l.castExpr = hashBool, "bool"
+	case info&types.IsString != 0:
+		l.hash, l.castExpr = hashString, "string"
+	}
+
+	return l, nil
+}
+
+// typeExpr returns the expression of the provided type (t) in the generated code, recording the
+// import of any package that it references.
+func (g *generator) typeExpr(t types.Type) string {
+	return types.TypeString(t, func(p *types.Package) string {
+		if p.Path() == g.pkg.Path() {
+			return ""
+		}
+
+		g.imports[p.Path()] = p.Name()
+		return p.Name()
+	})
+}
+
+// parseTag parses the raw value of an sql tag into its name and options, in the same way as
+// goscanql (so options are only separated by the commas outside parentheses and single quotes).
+func parseTag(raw string) (string, map[string]string) {
+	parts, _ := splitTag(raw)
+	options := make(map[string]string)
+
+	for _, part := range parts[1:] {
+		key, value, _ := strings.Cut(part, "=")
+		options[strings.TrimSpace(key)] = unquoteTagValue(value)
+	}
+
+	return parts[0], options
+}
+
+// splitTag splits the raw value of an sql tag on each of the commas that aren't within
+// parentheses or single quotes. It is a copy of goscanql's splitTag, which
+// TestTagFuncs_MatchGoscanql keeps it in step with. An error is returned (along with the parts as
+// far as they could be split) where the raw value has an unterminated quote or unbalanced
+// parentheses.
+func splitTag(raw string) ([]string, error) {
+	parts := make([]string, 0, 1)
+	depth := 0
+	quoted := false
+	start := 0
+
+	for i := 0; i < len(raw); i++ {
+		switch raw[i] {
+		case '\'':
+			// a quote within a quoted value is escaped by doubling it, which toggles twice
+			quoted = !quoted
+		case '(':
+			if !quoted {
+				depth++
+			}
+		case ')':
+			if !quoted {
+				depth--
+				if depth < 0 {
+					return append(parts, raw[start:]), fmt.Errorf("unbalanced parentheses in tag %q", raw)
+				}
+			}
+		case ',':
+			if !quoted && depth == 0 {
+				parts = append(parts, raw[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	parts = append(parts, raw[start:])
+
+	if quoted {
+		return parts, fmt.Errorf("unterminated quote in tag %q", raw)
+	}
+
+	if depth != 0 {
+		return parts, fmt.Errorf("unbalanced parentheses in tag %q", raw)
+	}
+
+	return parts, nil
+}
+
+// unquoteTagValue returns the provided tag option value with its quotes removed (and any doubled
+// quotes within it unescaped) where it is wholly single quoted, otherwise the value is returned
+// as it is. It is a copy of goscanql's unquoteTagValue.
+func unquoteTagValue(value string) string {
+	if len(value) < 2 || value[0] != '\'' || value[len(value)-1] != '\'' {
+		return value
+	}
+
+	inner := value[1 : len(value)-1]
+
+	// a lone quote within the value means that it isn't a single quoted string (e.g. 'a' || 'b')
+	if strings.Contains(strings.ReplaceAll(inner, "''", ""), "'") {
+		return value
+	}
+
+	return strings.ReplaceAll(inner, "''", "'")
+}
+
+// buildReferenceName joins the provided prefix and name in the same way as goscanql.
+func buildReferenceName(prefix, name string) string {
+	parts := make([]string, 0, 2)
+
+	if prefix != "" {
+		parts = append(parts, prefix)
+	}
+
+	if name != "" {
+		parts = append(parts, name)
+	}
+
+	return strings.Join(parts, "_")
+}
+
+// isStruct returns true if the underlying type of t is a struct.
+func isStruct(t types.Type) bool {
+	_, ok := t.Underlying().(*types.Struct)
+	return ok
+}
+
+// isSlice returns true if the underlying type of t is a slice.
+func isSlice(t types.Type) bool {
+	_, ok := t.Underlying().(*types.Slice)
+	return ok
+}
+
+// isTime returns true if t is time.Time.
+func isTime(t types.Type) bool {
+	named, ok := t.(*types.Named)
+	return ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == "time" && named.Obj().Name() == "Time"
+}
+
+// isPresence returns true if t is goscanql.Presence.
+func isPresence(t types.Type) bool {
+	named, ok := t.(*types.Named)
+	return ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == goscanqlPath && named.Obj().Name() == "Presence"
+}
+
+// isUnmarshaler returns true if a pointer to t implements encoding.TextUnmarshaler or
+// encoding.BinaryUnmarshaler (and isn't a Scanner or time.Time), and so would be decoded by
+// goscanql at runtime.
+func isUnmarshaler(t types.Type) bool {
+	if isScanner(t) || isTime(t) || hasMethod(t, "Scan", 1, 1) {
+		return false
+	}
+
+	return hasMethod(t, "UnmarshalText", 1, 1) || hasMethod(t, "UnmarshalBinary", 1, 1)
+}
+
+// isScanner returns true if t (or a pointer to t) implements goscanql.Scanner, i.e. has both
+// Scan(interface{}) error and ID() []byte methods.
+func isScanner(t types.Type) bool {
+	return hasMethod(t, "Scan", 1, 1) && hasMethod(t, "ID", 0, 1)
+}
+
+// hooksOf returns the names of the goscanql hooks that t (or a pointer to t) implements.
+func hooksOf(t types.Type) map[string]bool {
+	hooks := map[string]bool{}
+
+	for _, hook := range []string{"AfterScan", "AfterMerge", "Finalize", "Validate"} {
+		if hasMethod(t, hook, 0, 1) {
+			hooks[hook] = true
+		}
+	}
+
+	return hooks
+}
+
+// hasMethod returns true if a pointer to t has a method with the provided name, number of
+// parameters and number of results.
+func hasMethod(t types.Type, name string, params, results int) bool {
+	methods := types.NewMethodSet(types.NewPointer(t))
+
+	for i := 0; i < methods.Len(); i++ {
+		fn, ok := methods.At(i).Obj().(*types.Func)
+		if !ok || fn.Name() != name {
+			continue
+		}
+
+		sig := fn.Type().(*types.Signature)
+		return sig.Params().Len() == params && sig.Results().Len() == results
+	}
+
+	return false
+}
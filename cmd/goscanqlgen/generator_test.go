@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate_MatchesGeneratedFile(t *testing.T) {
+	// Arrange
+	expected, err := os.ReadFile(filepath.Join("internal", "parity", "goscanql_gen.go"))
+	if err != nil {
+		panic(err)
+	}
+
+	// Act
+	actual, err := Generate(filepath.Join("internal", "parity"), []string{"User"}, "goscanql_gen.go")
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, string(expected), string(actual), "goscanql_gen.go is stale, run go generate")
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	tests := []struct {
+		name        string
+		src         string
+		typeNames   []string
+		expectedErr string
+	}{
+		{
+			name:        "GivenUnknownType_ThenErrorReturned",
+			src:         "type Pet struct {\n\tName string `sql:\"name\"`\n}\n",
+			typeNames:   []string{"Owner"},
+			expectedErr: "type Owner not found",
+		},
+		{
+			name:        "GivenNoTaggedStructs_ThenErrorReturned",
+			src:         "type Pet struct {\n\tName string\n}\n",
+			expectedErr: "no tagged structs found in {dir}",
+		},
+		{
+			name:        "GivenOrderBy_ThenErrorReturned",
+			src:         "type Pet struct {\n\tName string `sql:\"name\"`\n}\n\ntype Owner struct {\n\tPets []Pet `sql:\"pets,orderby=name\"`\n}\n",
+			typeNames:   []string{"Owner"},
+			expectedErr: "the orderby tag option of Owner.Pets is not supported",
+		},
+		{
+			name:        "GivenNotNull_ThenErrorReturned",
+			src:         "type Pet struct {\n\tAge int `sql:\"age,notnull\"`\n}\n",
+			typeNames:   []string{"Pet"},
+			expectedErr: "the notnull tag option of Pet.Age is not supported",
+		},
+		{
+			name:        "GivenDefault_ThenErrorReturned",
+			src:         "type Pet struct {\n\tAge int `sql:\"age,default=1\"`\n}\n",
+			typeNames:   []string{"Pet"},
+			expectedErr: "the default tag option of Pet.Age is not supported",
+		},
+		{
+			name:        "GivenKey_ThenErrorReturned",
+			src:         "type Pet struct {\n\tID int `sql:\"id,key\"`\n}\n",
+			typeNames:   []string{"Pet"},
+			expectedErr: "the key tag option of Pet.ID is not supported",
+		},
+		{
+			name:        "GivenExtra_ThenErrorReturned",
+			src:         "type Pet struct {\n\tExtra map[string]any `sql:\",extra\"`\n}\n",
+			typeNames:   []string{"Pet"},
+			expectedErr: "the extra tag option of Pet.Extra is not supported",
+		},
+		{
+			name:        "GivenUnmarshaler_ThenErrorReturned",
+			src:         "type ID [4]byte\n\nfunc (id *ID) UnmarshalText(text []byte) error {\n\treturn nil\n}\n\ntype Pet struct {\n\tID ID `sql:\"id\"`\n}\n",
+			typeNames:   []string{"Pet"},
+			expectedErr: "field Pet.ID is decoded by UnmarshalText or UnmarshalBinary, which is not supported",
+		},
+		{
+			name:        "GivenOneToManyWithinOneToOne_ThenErrorReturned",
+			src:         "type Colour struct {\n\tShades []string `sql:\"shades\"`\n}\n\ntype Pet struct {\n\tColour Colour `sql:\"colour\"`\n}\n",
+			typeNames:   []string{"Pet"},
+			expectedErr: "field Colour.Shades is a one-to-many relationship within a one-to-one relationship, which is not supported",
+		},
+		{
+			name:        "GivenMap_ThenErrorReturned",
+			src:         "type Pet struct {\n\tTags map[string]string `sql:\"tags\"`\n}\n",
+			expectedErr: "field Pet.Tags: maps are not supported (map[string]string)",
+		},
+		{
+			name:        "GivenCollidingColumns_ThenErrorReturned",
+			src:         "type Colour struct {\n\tRed int `sql:\"red\"`\n}\n\ntype Pet struct {\n\tRed    int    `sql:\"colour_red\"`\n\tColour Colour `sql:\"colour\"`\n}\n",
+			typeNames:   []string{"Pet"},
+			expectedErr: "more than one field of Pet is mapped to the column colour_red",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Arrange
+			dir := t.TempDir()
+
+			err := os.WriteFile(filepath.Join(dir, "models.go"), []byte("package models\n\n"+test.src), 0o644)
+			if err != nil {
+				panic(err)
+			}
+
+			// Act
+			src, err := Generate(dir, test.typeNames, "goscanql_gen.go")
+
+			// Assert
+			assert.Nil(t, src)
+			assert.EqualError(t, err, strings.ReplaceAll(test.expectedErr, "{dir}", dir))
+		})
+	}
+}
+
+func TestParseTag(t *testing.T) {
+	tests := []struct {
+		name            string
+		input           string
+		expectedName    string
+		expectedOptions map[string]string
+	}{
+		{
+			name:            "GivenOptions_ThenSplitOnCommas",
+			input:           "toys,multiset",
+			expectedName:    "toys",
+			expectedOptions: map[string]string{"multiset": ""},
+		},
+		{
+			name:            "GivenCommaWithinParentheses_ThenOptionKeptWhole",
+			input:           "pets,on=pet.kind IN (1,2),multiset",
+			expectedName:    "pets",
+			expectedOptions: map[string]string{"on": "pet.kind IN (1,2)", "multiset": ""},
+		},
+		{
+			name:            "GivenCommaWithinQuotes_ThenValueUnquoted",
+			input:           "status,note='a,notnull'",
+			expectedName:    "status",
+			expectedOptions: map[string]string{"note": "a,notnull"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Act
+			name, options := parseTag(test.input)
+
+			// Assert
+			assert.Equal(t, test.expectedName, name)
+			assert.Equal(t, test.expectedOptions, options)
+		})
+	}
+}
+
+// funcSource returns the formatted signature and body (but not the doc comment) of the named
+// function in the provided file.
+func funcSource(t *testing.T, path, name string) string {
+	fset := token.NewFileSet()
+
+	file, err := parser.ParseFile(fset, path, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Name.Name != name {
+			continue
+		}
+
+		var buf bytes.Buffer
+
+		err := format.Node(&buf, fset, &ast.FuncDecl{Name: fn.Name, Type: fn.Type, Body: fn.Body})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return buf.String()
+	}
+
+	t.Fatalf("function %s not found in %s", name, path)
+	return ""
+}
+
+func TestTagFuncs_MatchGoscanql(t *testing.T) {
+	for _, name := range []string{"splitTag", "unquoteTagValue"} {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			expected := funcSource(t, filepath.Join("..", "..", "tag.go"), name)
+
+			// Act
+			actual := funcSource(t, "generator.go", name)
+
+			// Assert
+			assert.Equal(t, expected, actual, "%s has diverged from goscanql's %s", name, name)
+		})
+	}
+}
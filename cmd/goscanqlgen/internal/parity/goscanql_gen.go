@@ -0,0 +1,450 @@
+// Code generated by goscanqlgen. DO NOT EDIT.
+
+package parity
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/rustedturnip/goscanql"
+)
+
+// goscanqlgenUserColumns are the names of the columns that are mapped to User.
+var goscanqlgenUserColumns = []string{"id", "name", "active", "joined", "age", "colour_red", "colour_green", "colour_blue", "address_street", "address_postcode", "pets_id", "pets_name", "pets_adopted", "pets_colour_red", "pets_colour_green", "pets_colour_blue", "pets_toys_name", "pets_toys_nickname", "tags"}
+
+// ScanUser scans the provided rows into a slice of *User. It behaves in the same way as
+// goscanql.RowsToStructs[*User] (with the default options), but doesn't use reflection.
+func ScanUser(rows *sql.Rows) ([]*User, error) {
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	targets := goscanqlgenTargets(cols, goscanqlgenUserColumns)
+	entities := make([]*User, 0)
+	records := goscanqlgenRecords{}
+
+	for row := 1; rows.Next(); row++ {
+		r, err := goscanqlgenScanUserRow(rows.Scan, targets)
+		if err != nil {
+			return nil, err
+		}
+
+		if r == nil {
+			continue
+		}
+
+		err = r.merge0(&entities, records, row)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, e := range entities {
+		err := goscanqlgenFinalizeUser0(e)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return entities, nil
+}
+
+// goscanqlgenUserRow holds the entities of a single row scanned by ScanUser, and whether each of
+// them is live (i.e. it, and each of its parents, isn't nil).
+type goscanqlgenUserRow struct {
+	n0 *User
+	n1 *Colour
+	n2 *Address
+	n3 *Pet
+	n4 *Colour
+	n5 *Toy
+	n6 *string
+
+	live0 bool
+	live1 bool
+	live2 bool
+	live3 bool
+	live4 bool
+	live5 bool
+	live6 bool
+}
+
+// goscanqlgenScanUserRow scans the current row into a new goscanqlgenUserRow, returning nil if the
+// row doesn't hold a User.
+func goscanqlgenScanUserRow(scan func(...interface{}) error, targets []int) (*goscanqlgenUserRow, error) {
+	var discard interface{}
+
+	nulls := make([]bool, 19)
+
+	err := scan(goscanqlgenNullDest(targets, nulls, &discard)...)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &goscanqlgenUserRow{}
+	r.live0 = !(nulls[0] && nulls[1] && nulls[2] && nulls[3] && nulls[4])
+
+	if !r.live0 {
+		return nil, nil
+	}
+
+	r.live1 = r.live0 && !(nulls[5] && nulls[6] && nulls[7])
+	r.live2 = r.live0 && !(nulls[8] && nulls[9])
+	r.live3 = r.live0 && !(nulls[10] && nulls[11] && nulls[12])
+	r.live4 = r.live3 && !(nulls[13] && nulls[14] && nulls[15])
+	r.live5 = r.live3 && !(nulls[16] && nulls[17])
+	r.live6 = r.live0 && !(nulls[18])
+
+	r.n0 = new(User)
+	r.n0.Age = new(goscanql.NullInt64)
+	r.n1 = &r.n0.Colour
+	r.n0.Address = new(Address)
+	r.n2 = r.n0.Address
+	r.n3 = new(Pet)
+	r.n3.Adopted = new(time.Time)
+	r.n3.Colour = new(Colour)
+	r.n4 = r.n3.Colour
+	r.n5 = new(Toy)
+	r.n5.Nickname = new(string)
+	r.n6 = new(string)
+
+	refs := make([]interface{}, 19)
+
+	if r.live0 {
+		refs[0] = &r.n0.ID
+		refs[1] = &r.n0.Name
+		refs[2] = &r.n0.Active
+		refs[3] = &r.n0.Joined
+		refs[4] = r.n0.Age
+	}
+
+	if r.live1 {
+		refs[5] = &r.n1.Red
+		refs[6] = &r.n1.Green
+		refs[7] = &r.n1.Blue
+	}
+
+	if r.live2 {
+		refs[8] = &r.n2.Street
+		refs[9] = &r.n2.Postcode
+	}
+
+	if r.live3 {
+		refs[10] = &r.n3.ID
+		refs[11] = &r.n3.Name
+		refs[12] = &r.n3.Adopted
+	}
+
+	if r.live4 {
+		refs[13] = &r.n4.Red
+		refs[14] = &r.n4.Green
+		refs[15] = &r.n4.Blue
+	}
+
+	if r.live5 {
+		refs[16] = &r.n5.Name
+		refs[17] = &r.n5.Nickname
+	}
+
+	if r.live6 {
+		refs[18] = r.n6
+	}
+
+	err = scan(goscanqlgenDest(targets, refs, &discard)...)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.live0 && !r.live1 {
+		*r.n1 = Colour{}
+	}
+
+	if r.live0 && !r.live2 {
+		r.n0.Address = nil
+	}
+
+	if r.live3 && !r.live4 {
+		r.n3.Colour = nil
+	}
+
+	if r.live3 {
+		if err := r.n3.AfterScan(); err != nil {
+			return nil, fmt.Errorf("goscanql: AfterScan failed for parity.Pet: %w", err)
+		}
+	}
+
+	return r, nil
+}
+
+func (r *goscanqlgenUserRow) key0(b []byte) []byte {
+	b = goscanqlgenAppendInt(b, int64(r.n0.ID))
+	b = goscanqlgenAppendString(b, r.n0.Name)
+	b = goscanqlgenAppendBool(b, r.n0.Active)
+	b = goscanqlgenAppendString(b, r.n0.Joined.UTC().Format(time.RFC3339Nano))
+	if r.n0.Age != nil {
+		b = goscanqlgenAppendString(b, string(r.n0.Age.ID()))
+	} else {
+		b = goscanqlgenAppendString(b, string(new(goscanql.NullInt64).ID()))
+	}
+	b = r.key1(b)
+	b = r.key2(b)
+	return b
+}
+
+func (r *goscanqlgenUserRow) key1(b []byte) []byte {
+	b = goscanqlgenAppendInt(b, int64(r.n1.Red))
+	b = goscanqlgenAppendUint(b, uint64(r.n1.Green))
+	b = goscanqlgenAppendFloat(b, r.n1.Blue)
+	return b
+}
+
+func (r *goscanqlgenUserRow) key2(b []byte) []byte {
+	b = goscanqlgenAppendString(b, r.n2.Street)
+	b = goscanqlgenAppendString(b, string(r.n2.Postcode.ID()))
+	return b
+}
+
+func (r *goscanqlgenUserRow) key3(b []byte) []byte {
+	b = goscanqlgenAppendInt(b, r.n3.ID)
+	b = goscanqlgenAppendString(b, r.n3.Name)
+	if t := r.n3.Adopted; t != nil {
+		b = goscanqlgenAppendString(b, t.UTC().Format(time.RFC3339Nano))
+	} else {
+		b = goscanqlgenAppendString(b, fmt.Sprintf("%#v", t))
+	}
+	b = r.key4(b)
+	return b
+}
+
+func (r *goscanqlgenUserRow) key4(b []byte) []byte {
+	b = goscanqlgenAppendInt(b, int64(r.n4.Red))
+	b = goscanqlgenAppendUint(b, uint64(r.n4.Green))
+	b = goscanqlgenAppendFloat(b, r.n4.Blue)
+	return b
+}
+
+func (r *goscanqlgenUserRow) key5(b []byte) []byte {
+	b = goscanqlgenAppendString(b, r.n5.Name)
+	b = goscanqlgenAppendString(b, fmt.Sprintf("%#v", r.n5.Nickname))
+	return b
+}
+
+func (r *goscanqlgenUserRow) key6(b []byte) []byte {
+	b = goscanqlgenAppendString(b, *r.n6)
+	return b
+}
+
+func (r *goscanqlgenUserRow) merge0(entities *[]*User, records goscanqlgenRecords, row int) error {
+	if !r.live0 {
+		return nil
+	}
+
+	key := string(r.key0(nil))
+	record, ok := records[key]
+
+	if !ok {
+		record = goscanqlgenNewRecord(len(records), 2)
+		records[key] = record
+		*entities = append(*entities, r.n0)
+	}
+
+	e := (*entities)[record.index]
+
+	if err := r.merge3(&e.Pets, record.children[0], row); err != nil {
+		return err
+	}
+
+	if err := r.merge6(&e.Tags, record.children[1], row); err != nil {
+		return err
+	}
+
+	if ok {
+		if err := e.AfterMerge(); err != nil {
+			return fmt.Errorf("goscanql: AfterMerge failed for parity.User: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (r *goscanqlgenUserRow) merge3(entities *[]*Pet, records goscanqlgenRecords, row int) error {
+	if !r.live3 {
+		return nil
+	}
+
+	key := string(r.key3(nil))
+	record, ok := records[key]
+
+	if !ok {
+		record = goscanqlgenNewRecord(len(records), 1)
+		records[key] = record
+		*entities = append(*entities, r.n3)
+	}
+
+	e := (*entities)[record.index]
+
+	if err := r.merge5(&e.Toys, record.children[0], row); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *goscanqlgenUserRow) merge5(entities *[]Toy, records goscanqlgenRecords, row int) error {
+	if !r.live5 {
+		return nil
+	}
+
+	key := goscanqlgenMultisetKey(r.key5(nil), row)
+
+	if _, ok := records[key]; !ok {
+		records[key] = goscanqlgenNewRecord(len(records), 0)
+		*entities = append(*entities, *r.n5)
+	}
+
+	return nil
+}
+
+func (r *goscanqlgenUserRow) merge6(entities *[]string, records goscanqlgenRecords, row int) error {
+	if !r.live6 {
+		return nil
+	}
+
+	key := string(r.key6(nil))
+
+	if _, ok := records[key]; !ok {
+		records[key] = goscanqlgenNewRecord(len(records), 0)
+		*entities = append(*entities, *r.n6)
+	}
+
+	return nil
+}
+
+func goscanqlgenFinalizeUser0(e *User) error {
+	if err := e.Finalize(); err != nil {
+		return fmt.Errorf("goscanql: Finalize failed for parity.User: %w", err)
+	}
+
+	if err := e.Validate(); err != nil {
+		return fmt.Errorf("goscanql: Validate failed for parity.User: %w", err)
+	}
+
+	return nil
+}
+
+// goscanqlgenNull records whether the value of a column is null.
+type goscanqlgenNull bool
+
+func (n *goscanqlgenNull) Scan(value interface{}) error {
+	*n = value == nil
+	return nil
+}
+
+// goscanqlgenRecords maps the key of each entity of a slice to its record.
+type goscanqlgenRecords map[string]*goscanqlgenRecord
+
+// goscanqlgenRecord records the position of an entity in its slice, and the records of each of
+// its one-to-many children.
+type goscanqlgenRecord struct {
+	index    int
+	children []goscanqlgenRecords
+}
+
+func goscanqlgenNewRecord(index, children int) *goscanqlgenRecord {
+	r := &goscanqlgenRecord{
+		index:    index,
+		children: make([]goscanqlgenRecords, children),
+	}
+
+	for i := range r.children {
+		r.children[i] = goscanqlgenRecords{}
+	}
+
+	return r
+}
+
+// goscanqlgenTargets returns the index of each of the provided columns (cols) within the mapped
+// columns, or -1 where a column isn't mapped.
+func goscanqlgenTargets(cols, columns []string) []int {
+	targets := make([]int, len(cols))
+
+	for i, col := range cols {
+		targets[i] = -1
+
+		for j, column := range columns {
+			if col == column {
+				targets[i] = j
+				break
+			}
+		}
+	}
+
+	return targets
+}
+
+// goscanqlgenNullDest returns the destinations that record whether each mapped column is null.
+func goscanqlgenNullDest(targets []int, nulls []bool, discard *interface{}) []interface{} {
+	for i := range nulls {
+		nulls[i] = true
+	}
+
+	dest := make([]interface{}, len(targets))
+
+	for i, target := range targets {
+		if target < 0 {
+			dest[i] = discard
+			continue
+		}
+
+		dest[i] = (*goscanqlgenNull)(&nulls[target])
+	}
+
+	return dest
+}
+
+// goscanqlgenDest returns the destinations of each of the columns, discarding any column that
+// isn't mapped (or belongs to an entity that isn't live).
+func goscanqlgenDest(targets []int, refs []interface{}, discard *interface{}) []interface{} {
+	dest := make([]interface{}, len(targets))
+
+	for i, target := range targets {
+		if target < 0 || refs[target] == nil {
+			dest[i] = discard
+			continue
+		}
+
+		dest[i] = refs[target]
+	}
+
+	return dest
+}
+
+func goscanqlgenMultisetKey(b []byte, row int) string {
+	b = append(b, '#')
+	return string(strconv.AppendInt(b, int64(row), 10))
+}
+
+func goscanqlgenAppendString(b []byte, s string) []byte {
+	b = strconv.AppendInt(b, int64(len(s)), 10)
+	b = append(b, ':')
+	return append(b, s...)
+}
+
+func goscanqlgenAppendInt(b []byte, i int64) []byte {
+	return append(strconv.AppendInt(b, i, 10), ';')
+}
+
+func goscanqlgenAppendUint(b []byte, u uint64) []byte {
+	return append(strconv.AppendUint(b, u, 10), ';')
+}
+
+func goscanqlgenAppendFloat(b []byte, f float64) []byte {
+	return append(strconv.AppendFloat(b, f, 'g', -1, 64), ';')
+}
+
+func goscanqlgenAppendBool(b []byte, v bool) []byte {
+	return append(strconv.AppendBool(b, v), ';')
+}
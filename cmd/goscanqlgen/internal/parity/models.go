@@ -0,0 +1,74 @@
+// Package parity holds the models that the scan functions generated by goscanqlgen are tested
+// against, to ensure that they behave in the same way as goscanql.RowsToStructs.
+package parity
+
+import (
+	"errors"
+	"time"
+
+	"github.com/rustedturnip/goscanql"
+)
+
+//go:generate go run github.com/rustedturnip/goscanql/cmd/goscanqlgen -type User
+
+type Colour struct {
+	Red   int     `sql:"red"`
+	Green uint8   `sql:"green"`
+	Blue  float64 `sql:"blue"`
+}
+
+type Address struct {
+	Street   string              `sql:"street"`
+	Postcode goscanql.NullString `sql:"postcode"`
+}
+
+type Toy struct {
+	Name     string  `sql:"name"`
+	Nickname *string `sql:"nickname"`
+}
+
+type Pet struct {
+	ID      int64      `sql:"id"`
+	Name    string     `sql:"name"`
+	Adopted *time.Time `sql:"adopted"`
+	Colour  *Colour    `sql:"colour"`
+	Toys    []Toy      `sql:"toys,multiset"`
+	Scans   int
+}
+
+func (p *Pet) AfterScan() error {
+	p.Scans++
+	return nil
+}
+
+type User struct {
+	ID       int                 `sql:"id"`
+	Name     string              `sql:"name"`
+	Active   bool                `sql:"active"`
+	Joined   time.Time           `sql:"joined"`
+	Age      *goscanql.NullInt64 `sql:"age"`
+	Colour   Colour              `sql:"colour"`
+	Address  *Address            `sql:"address"`
+	Pets     []*Pet              `sql:"pets"`
+	Tags     []string            `sql:"tags"`
+	Merges   int
+	PetCount int
+}
+
+func (u *User) AfterMerge() error {
+	u.Merges++
+	return nil
+}
+
+func (u *User) Finalize() error {
+	u.PetCount = len(u.Pets)
+	return nil
+}
+
+func (u *User) Validate() error {
+	if u.Name == "" {
+		return errors.New("name is required")
+	}
+
+	return nil
+}
@@ -0,0 +1,178 @@
+package parity
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/rustedturnip/goscanql"
+	"github.com/stretchr/testify/assert"
+)
+
+var parityColumns = []string{
+	"id", "name", "active", "joined", "age",
+	"colour_red", "colour_green", "colour_blue",
+	"address_street", "address_postcode",
+	"pets_id", "pets_name", "pets_adopted", "pets_colour_red", "pets_colour_green", "pets_colour_blue",
+	"pets_toys_name", "pets_toys_nickname",
+	"tags", "unmapped",
+}
+
+// queryTwice returns two sets of rows with the provided columns and values, so that the same
+// rows can be scanned by both goscanql and the generated code.
+func queryTwice(columns []string, values [][]driver.Value) (*sql.Rows, *sql.Rows) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		panic(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		rows := sqlmock.NewRows(columns)
+		for _, row := range values {
+			rows.AddRow(row...)
+		}
+
+		mock.ExpectQuery("SELECT").WillReturnRows(rows)
+	}
+
+	first, err := db.Query("SELECT")
+	if err != nil {
+		panic(err)
+	}
+
+	second, err := db.Query("SELECT")
+	if err != nil {
+		panic(err)
+	}
+
+	return first, second
+}
+
+func TestScanUser(t *testing.T) {
+	joined := time.Date(2009, 11, 10, 23, 0, 0, 0, time.UTC)
+	adopted := time.Date(2012, 6, 1, 9, 0, 0, 0, time.UTC)
+
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		panic(err)
+	}
+
+	tests := []struct {
+		name        string
+		columns     []string
+		rows        [][]driver.Value
+		expectedLen int
+	}{
+		{
+			name:    "GivenOneToManyRows_ThenEntitiesMerged",
+			columns: parityColumns,
+			rows: [][]driver.Value{
+				{1, "Sterling", true, joined, 38, 255, 128, 0.5, "Cheshire Lane", "CH1", 1, "Babou", nil, 10, 20, 0.25, "Ball", "Bally", "spy", "x"},
+				{1, "Sterling", true, joined, 38, 255, 128, 0.5, "Cheshire Lane", "CH1", 1, "Babou", nil, 10, 20, 0.25, "Ball", "Bally", "agent", "x"},
+				{1, "Sterling", true, joined, 38, 255, 128, 0.5, "Cheshire Lane", "CH1", 1, "Ocelot", nil, 10, 20, 0.25, "Mouse", nil, "spy", "x"},
+				{1, "Sterling", true, joined, 38, 255, 128, 0.5, "Cheshire Lane", "CH1", 2, "Gustavo", nil, nil, nil, nil, nil, nil, nil, "x"},
+				{2, "Lana", false, joined, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, "x"},
+				{2, "Lana", false, joined, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, "x"},
+			},
+			expectedLen: 2,
+		},
+		{
+			name:    "GivenNilRoots_ThenRowsSkipped",
+			columns: parityColumns,
+			rows: [][]driver.Value{
+				{nil, nil, nil, nil, nil, 255, 128, 0.5, nil, nil, 1, "Babou", nil, nil, nil, nil, nil, nil, "spy", "x"},
+				{3, "Cyril", false, joined, 40, nil, nil, nil, "Figgis Street", nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, "x"},
+			},
+			expectedLen: 1,
+		},
+		{
+			name:    "GivenPointerTimesInDifferentZones_ThenEntitiesMerged",
+			columns: []string{"id", "name", "pets_id", "pets_name", "pets_adopted", "tags"},
+			rows: [][]driver.Value{
+				{1, "Sterling", 1, "Babou", adopted, "spy"},
+				{1, "Sterling", 1, "Babou", adopted.In(newYork), "agent"},
+				{1, "Sterling", 2, "Gustavo", nil, "spy"},
+			},
+			expectedLen: 1,
+		},
+		{
+			name:    "GivenMissingColumns_ThenFieldsLeftEmpty",
+			columns: []string{"tags", "name", "pets_id", "pets_toys_name", "id"},
+			rows: [][]driver.Value{
+				{"spy", "Sterling", 1, "Ball", 1},
+				{"spy", "Sterling", 1, "Ball", 1},
+				{"agent", "Sterling", 2, nil, 1},
+			},
+			expectedLen: 1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Arrange
+			reflected, generated := queryTwice(test.columns, test.rows)
+
+			expected, expectedErr := goscanql.RowsToStructs[*User](reflected)
+
+			// Act
+			actual, err := ScanUser(generated)
+
+			// Assert
+			assert.Nil(t, expectedErr)
+			assert.Nil(t, err)
+			assert.Len(t, actual, test.expectedLen)
+			assert.Equal(t, expected, actual)
+		})
+	}
+}
+
+func TestScanUser_HookError(t *testing.T) {
+	// Arrange
+	reflected, generated := queryTwice([]string{"id", "name"}, [][]driver.Value{{1, ""}})
+
+	_, expectedErr := goscanql.RowsToStructs[*User](reflected)
+
+	// Act
+	actual, err := ScanUser(generated)
+
+	// Assert
+	assert.Nil(t, actual)
+	assert.EqualError(t, err, "goscanql: Validate failed for parity.User: name is required")
+	assert.Equal(t, expectedErr, err)
+}
+
+func TestScanUser_Merged(t *testing.T) {
+	// Arrange
+	joined := time.Date(2009, 11, 10, 23, 0, 0, 0, time.UTC)
+
+	_, generated := queryTwice(parityColumns, [][]driver.Value{
+		{1, "Sterling", true, joined, 38, 255, 128, 0.5, nil, nil, 1, "Babou", nil, nil, nil, nil, "Ball", nil, "spy", nil},
+		{1, "Sterling", true, joined, 38, 255, 128, 0.5, nil, nil, 1, "Babou", nil, nil, nil, nil, "Ball", nil, "agent", nil},
+		{1, "Sterling", true, joined, 38, 255, 128, 0.5, nil, nil, 2, "Gustavo", nil, nil, nil, nil, nil, nil, "spy", nil},
+	})
+
+	// Act
+	actual, err := ScanUser(generated)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []*User{
+		{
+			ID:     1,
+			Name:   "Sterling",
+			Active: true,
+			Joined: joined,
+			Age:    &goscanql.NullInt64{Int64: 38, Valid: true},
+			Colour: Colour{Red: 255, Green: 128, Blue: 0.5},
+			Pets: []*Pet{
+				{ID: 1, Name: "Babou", Toys: []Toy{{Name: "Ball"}, {Name: "Ball"}}, Scans: 1},
+				{ID: 2, Name: "Gustavo", Scans: 1},
+			},
+			Tags:     []string{"spy", "agent"},
+			Merges:   2,
+			PetCount: 2,
+		},
+	}, actual)
+}
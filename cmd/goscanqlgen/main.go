@@ -0,0 +1,50 @@
+// Command goscanqlgen generates reflection-free scan functions for the tagged structs of a
+// package, which behave in the same way as goscanql.RowsToStructs. It is intended to be run with
+// go:generate, for example:
+//
+//	//go:generate goscanqlgen -type User,Pet
+//
+// For each of the types, a function of the form:
+//
+//	func ScanUser(rows *sql.Rows) ([]*User, error)
+//
+// is generated, which is equivalent to goscanql.RowsToStructs[*User](rows) (with the default
+// options), but doesn't use reflection at runtime. Where no types are provided, a function is
+// generated for every struct type of the package with an sql tag.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	typeNames := flag.String("type", "", "comma separated list of type names (defaults to every tagged struct)")
+	output := flag.String("output", "goscanql_gen.go", "name of the file to write to (within the package directory)")
+	flag.Parse()
+
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+
+	var types []string
+	if *typeNames != "" {
+		types = strings.Split(*typeNames, ",")
+	}
+
+	src, err := Generate(dir, types, *output)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "goscanqlgen: %s\n", err)
+		os.Exit(1)
+	}
+
+	err = os.WriteFile(filepath.Join(dir, *output), src, 0o644)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "goscanqlgen: %s\n", err)
+		os.Exit(1)
+	}
+}
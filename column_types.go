@@ -0,0 +1,197 @@
+package goscanql
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// sqlScannerType is the type of the sql.Scanner interface.
+var sqlScannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+// nullValueTypes maps each of the sql.Null types (as reported by the ScanType of a column) to the
+// type of the value that it holds.
+var nullValueTypes = map[reflect.Type]reflect.Type{
+	reflect.TypeOf(sql.NullString{}):  reflect.TypeOf(""),
+	reflect.TypeOf(sql.NullInt64{}):   reflect.TypeOf(int64(0)),
+	reflect.TypeOf(sql.NullInt32{}):   reflect.TypeOf(int32(0)),
+	reflect.TypeOf(sql.NullInt16{}):   reflect.TypeOf(int16(0)),
+	reflect.TypeOf(sql.NullByte{}):    reflect.TypeOf(byte(0)),
+	reflect.TypeOf(sql.NullFloat64{}): reflect.TypeOf(float64(0)),
+	reflect.TypeOf(sql.NullBool{}):    reflect.TypeOf(false),
+	reflect.TypeOf(sql.NullTime{}):    timeType,
+}
+
+// valueClass represents a class of values that can be scanned into one another without any loss
+// of meaning.
+type valueClass int
+
+const (
+	unknownClass valueClass = iota
+	intClass
+	floatClass
+	boolClass
+	stringClass
+	timeClass
+)
+
+// classOf returns the valueClass of the provided type (t).
+func classOf(t reflect.Type) valueClass {
+	if t == timeType {
+		return timeClass
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return intClass
+	case reflect.Float32, reflect.Float64:
+		return floatClass
+	case reflect.Bool:
+		return boolClass
+	case reflect.String:
+		return stringClass
+	}
+
+	return unknownClass
+}
+
+// acceptedClasses maps the valueClass of each field to the classes of column that it accepts.
+var acceptedClasses = map[valueClass][]valueClass{
+	intClass:    {intClass},
+	floatClass:  {intClass, floatClass},
+	boolClass:   {boolClass, intClass},
+	stringClass: {stringClass},
+	timeClass:   {timeClass},
+}
+
+// rowsColumns returns the columns of the provided rows, validating their types against the type
+// (t) that they are scanned into where the WithColumnTypeValidation option is provided.
+func rowsColumns(rows *sql.Rows, t reflect.Type, o *options) ([]string, error) {
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	if o == nil || !o.validateColumnTypes {
+		return cols, nil
+	}
+
+	colTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	err = validateColumnTypes(t, colTypes, o)
+	if err != nil {
+		return nil, err
+	}
+
+	return cols, nil
+}
+
+// validateColumnTypes compares the provided column types with the fields of the provided type (t)
+// that they are scanned into, returning an error describing every mismatch (or nil if there are
+// none).
+func validateColumnTypes(t reflect.Type, colTypes []*sql.ColumnType, o *options) error {
+	columns := make(map[string]column)
+	for _, c := range collectColumns(getPointerRootType(t), "", o) {
+		columns[o.columnKey(c.name)] = c
+	}
+
+	problems := make([]error, 0)
+
+	for _, colType := range colTypes {
+		c, ok := columns[o.columnKey(colType.Name())]
+		if !ok {
+			continue
+		}
+
+		err := validateColumnType(c, colType, o)
+		if err != nil {
+			problems = append(problems, err)
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("goscanql: columns don't match %s:\n%w", getPointerRootType(t).String(), errors.Join(problems...))
+}
+
+// validateColumnType compares the type of a single column (colType) with the field that it is
+// scanned into (c).
+func validateColumnType(c column, colType *sql.ColumnType, o *options) error {
+	target := c.field.field.Type
+	if c.field.kind == oneToManyKind {
+		target = getPointerRootType(target).Elem()
+	}
+
+	// the values of fields with a converter (including null) are for the converter to interpret
+	if o.hasConverter(target) {
+		return nil
+	}
+
+	field := fmt.Sprintf("%s (%s)", c.field.field.Name, target.String())
+	problems := make([]error, 0)
+
+	// a null column can only be held by a field of the root entity that can be nil or that has a
+	// default value (the values of children being null is how goscanql detects that the children
+	// are nil)
+	_, hasDefault := c.field.tag.get(defaultTagOption)
+
+	if nullable, ok := colType.Nullable(); ok && nullable && c.prefix == "" && !isNullable(target) && !hasDefault {
+		problems = append(problems, fmt.Errorf("column %s is nullable, but field %s can't hold null", c.name, field))
+	}
+
+	if err := validateScanType(c, field, target, colType); err != nil {
+		problems = append(problems, err)
+	}
+
+	return errors.Join(problems...)
+}
+
+// validateScanType compares the ScanType of a column (colType) with the type of the field that it
+// is scanned into (target), returning an error if the column's values would lose their meaning.
+func validateScanType(c column, field string, target reflect.Type, colType *sql.ColumnType) error {
+	scanType := colType.ScanType()
+	if scanType == nil {
+		return nil
+	}
+
+	if valueType, ok := nullValueTypes[scanType]; ok {
+		scanType = valueType
+	}
+
+	target = getPointerRootType(target)
+
+	if valueType, ok := scannerValueTypes[reflect.PointerTo(target)]; ok {
+		target = valueType
+	}
+
+	accepted, ok := acceptedClasses[classOf(target)]
+	if !ok || classOf(scanType) == unknownClass || reflect.PointerTo(target).Implements(sqlScannerType) {
+		return nil
+	}
+
+	for _, class := range accepted {
+		if classOf(scanType) == class {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("column %s holds %s, which can't be scanned into field %s", c.name, scanType.String(), field)
+}
+
+// isNullable returns true if a field of the provided type (t) can hold null, i.e. it is a pointer,
+// an interface, or a Scanner.
+func isNullable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return true
+	}
+
+	return reflect.PointerTo(t).Implements(sqlScannerType)
+}
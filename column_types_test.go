@@ -0,0 +1,107 @@
+package goscanql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+type columnTypesTestPet struct {
+	Name string `sql:"name"`
+	Age  int    `sql:"age"`
+}
+
+type columnTypesTestAccount struct {
+	ID          int                  `sql:"id"`
+	Age         int                  `sql:"age"`
+	Email       *string              `sql:"email"`
+	Nickname    NullString           `sql:"nickname"`
+	Balance     string               `sql:"balance"`
+	Status      string               `sql:"status,default=active"`
+	DateOfBirth time.Time            `sql:"date_of_birth"`
+	Pets        []columnTypesTestPet `sql:"pets"`
+	Aliases     []string             `sql:"aliases"`
+}
+
+func TestWithColumnTypeValidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		columns     []*sqlmock.Column
+		opts        []Option
+		expectedErr string
+	}{
+		{
+			name: "GivenMatchingColumns_ThenNoError",
+			columns: []*sqlmock.Column{
+				sqlmock.NewColumn("id").OfType("INT", int64(0)).Nullable(false),
+				sqlmock.NewColumn("age").OfType("INT", int32(0)).Nullable(false),
+				sqlmock.NewColumn("email").OfType("VARCHAR", "").Nullable(true),
+				sqlmock.NewColumn("nickname").OfType("VARCHAR", "").Nullable(true),
+				sqlmock.NewColumn("balance").OfType("VARCHAR", "").Nullable(false),
+				sqlmock.NewColumn("status").OfType("VARCHAR", "").Nullable(true),
+				sqlmock.NewColumn("date_of_birth").OfType("DATE", time.Time{}).Nullable(false),
+				sqlmock.NewColumn("pets_name").OfType("VARCHAR", "").Nullable(true),
+				sqlmock.NewColumn("pets_age").OfType("INT", int64(0)).Nullable(true),
+				sqlmock.NewColumn("aliases").OfType("VARCHAR", "").Nullable(true),
+				sqlmock.NewColumn("unmapped").OfType("FLOAT", float64(0)).Nullable(true),
+			},
+			opts: []Option{WithColumnTypeValidation()},
+		},
+		{
+			name: "GivenMismatchedColumns_ThenEveryProblemReported",
+			columns: []*sqlmock.Column{
+				sqlmock.NewColumn("id").OfType("INT", int64(0)).Nullable(false),
+				sqlmock.NewColumn("age").OfType("INT", int64(0)).Nullable(true),
+				sqlmock.NewColumn("nickname").OfType("INT", int64(0)).Nullable(true),
+				sqlmock.NewColumn("balance").OfType("DECIMAL", float64(0)).Nullable(false),
+				sqlmock.NewColumn("date_of_birth").OfType("VARCHAR", "").Nullable(true),
+				sqlmock.NewColumn("pets_age").OfType("VARCHAR", "").Nullable(true),
+			},
+			opts: []Option{WithColumnTypeValidation()},
+			expectedErr: "goscanql: columns don't match goscanql.columnTypesTestAccount:\n" +
+				"column age is nullable, but field Age (int) can't hold null\n" +
+				"column nickname holds int64, which can't be scanned into field Nickname (goscanql.NullString)\n" +
+				"column balance holds float64, which can't be scanned into field Balance (string)\n" +
+				"column date_of_birth is nullable, but field DateOfBirth (time.Time) can't hold null\n" +
+				"column date_of_birth holds string, which can't be scanned into field DateOfBirth (time.Time)\n" +
+				"column pets_age holds string, which can't be scanned into field Age (int)",
+		},
+		{
+			name: "GivenMismatchedColumnsWithoutOption_ThenNoError",
+			columns: []*sqlmock.Column{
+				sqlmock.NewColumn("id").OfType("INT", int64(0)).Nullable(false),
+				sqlmock.NewColumn("balance").OfType("DECIMAL", float64(0)).Nullable(false),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Arrange
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				panic(err)
+			}
+
+			mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRowsWithColumnDefinition(test.columns...))
+
+			rows, err := db.Query("SELECT")
+			if err != nil {
+				panic(err)
+			}
+
+			// Act
+			_, err = RowsToStructs[*columnTypesTestAccount](rows, test.opts...)
+
+			// Assert
+			if test.expectedErr == "" {
+				assert.Nil(t, err)
+				return
+			}
+
+			assert.EqualError(t, err, test.expectedErr)
+		})
+	}
+}
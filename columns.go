@@ -0,0 +1,170 @@
+package goscanql
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// column represents a single column that goscanql expects to read for a type.
+type column struct {
+
+	// name is the name that the column must be selected as (its alias), e.g. pets_colour_red.
+	name string
+
+	// prefix is the prefix of the struct that the column belongs to, e.g. pets_colour.
+	prefix string
+
+	// field is the struct field that the column is scanned into.
+	field structField
+}
+
+// collectColumns walks the fields of the provided type (t) in the order that they are declared
+// (as newFields would map them), and returns every column that goscanql would read for it. The
+// provided prefix is the prefix of the type itself.
+func collectColumns(t reflect.Type, prefix string, o *options) []column {
+	columns := make([]column, 0)
+
+	for _, field := range structFields(t, o) {
+		name := buildReferenceName(prefix, field.tag.name)
+
+		switch field.kind {
+		case oneToOneKind:
+			columns = append(columns, collectColumns(field.elemType(), name, o)...)
+
+		case oneToManyKind:
+			// slices of values (e.g. []string) are scanned from a single column named after the
+			// slice itself
+			if kindOf(field.elemType(), o) != oneToOneKind {
+				columns = append(columns, column{name: name, prefix: name, field: field})
+				continue
+			}
+
+			columns = append(columns, collectColumns(field.elemType(), name, o)...)
+
+		default:
+			columns = append(columns, column{name: name, prefix: prefix, field: field})
+		}
+	}
+
+	return columns
+}
+
+// validateColumnCases ensures that, where columns are matched regardless of case (see
+// WithCaseInsensitiveColumns), no two fields of the provided type (t) are mapped to columns whose
+// names only differ by case, as only one of them could be matched.
+func validateColumnCases(t reflect.Type, o *options) error {
+	if o == nil || !o.caseInsensitive {
+		return nil
+	}
+
+	seen := make(map[string]column)
+
+	for _, c := range collectColumns(t, "", o) {
+		key := o.columnKey(c.name)
+
+		if other, ok := seen[key]; ok && other.name != c.name {
+			return fmt.Errorf("goscanql: fields %s and %s are mapped to columns that only differ by case (%s and %s)",
+				other.field.field.Name, c.field.field.Name, other.name, c.name)
+		}
+
+		seen[key] = c
+	}
+
+	return nil
+}
+
+// source returns the (table qualified) expression that the column should be selected from, based
+// on the provided table aliases (keyed by prefix). Where the column's own prefix has no table
+// alias, the nearest parent prefix that does is used, and the column is named relative to that
+// parent (e.g. a one-to-one Address stored in the same table as its parent would be selected as
+// account.address_street).
+func (c column) source(tables map[string]string, quote func(string) string) string {
+	prefix := c.prefix
+	if _, ok := tables[prefix]; !ok {
+		prefix = parentPrefix(prefix, tables)
+	}
+
+	name := c.field.tag.name
+
+	// values of a slice (e.g. []string) are named after the slice itself, so are only named
+	// relative to the prefix when selected from the table of a parent
+	if c.name != prefix {
+		name = trimPrefix(c.name, prefix)
+	}
+
+	table := tables[prefix]
+	if table == "" {
+		return quote(name)
+	}
+
+	return fmt.Sprintf("%s.%s", quote(table), quote(name))
+}
+
+// parentPrefix returns the longest prefix in tables that is a parent of the provided prefix, or
+// an empty string if there is none.
+func parentPrefix(prefix string, tables map[string]string) string {
+	longest := ""
+
+	for candidate := range tables {
+		if candidate == "" || len(candidate) <= len(longest) || len(candidate) >= len(prefix) {
+			continue
+		}
+
+		if strings.HasPrefix(prefix, candidate+"_") {
+			longest = candidate
+		}
+	}
+
+	return longest
+}
+
+// trimPrefix removes the provided prefix (and the separating underscore) from name.
+func trimPrefix(name, prefix string) string {
+	if prefix == "" {
+		return name
+	}
+
+	return name[len(prefix)+1:]
+}
+
+// Columns returns a list of aliased select expressions for each of the columns that goscanql
+// expects to read for the type T, e.g. "pet.name AS pets_name". The provided tables map each
+// prefix to the alias of the table that its columns are selected from, for example:
+//
+//	goscanql.Columns[Account](map[string]string{
+//		"":            "account",
+//		"pets":        "pet",
+//		"pets_colour": "colour",
+//	})
+//
+// Where a prefix has no table, the table of its nearest parent prefix is used. The expressions
+// are returned in the order that the fields of T are declared.
+func Columns[T any](tables map[string]string, opts ...Option) ([]string, error) {
+	var zero T
+
+	o := newOptions(opts)
+
+	err := validateType(zero, o)
+	if err != nil {
+		return nil, err
+	}
+
+	return selectExpressions(collectColumns(reflect.TypeOf(zero), "", o), tables, noQuote), nil
+}
+
+// selectExpressions builds an aliased select expression for each of the provided columns.
+func selectExpressions(columns []column, tables map[string]string, quote func(string) string) []string {
+	expressions := make([]string, len(columns))
+
+	for i, c := range columns {
+		expressions[i] = fmt.Sprintf("%s AS %s", c.source(tables, quote), quote(c.name))
+	}
+
+	return expressions
+}
+
+// noQuote returns the provided identifier as it is.
+func noQuote(identifier string) string {
+	return identifier
+}
@@ -0,0 +1,173 @@
+package goscanql
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type columnsTestColour struct {
+	Name string `sql:"name"`
+	Red  int    `sql:"red"`
+}
+
+type columnsTestPet struct {
+	Name   string             `sql:"name"`
+	Colour *columnsTestColour `sql:"colour"`
+}
+
+type columnsTestAddress struct {
+	Street string `sql:"street"`
+}
+
+type columnsTestAccount struct {
+	ID          int                `sql:"id"`
+	Nickname    NullString         `sql:"nickname"`
+	Address     columnsTestAddress `sql:"address"`
+	Aliases     []string           `sql:"alias"`
+	Pets        []*columnsTestPet  `sql:"pets"`
+	Ignored     string
+	DateOfBirth string `sql:"-"`
+}
+
+func TestColumns(t *testing.T) {
+	tests := []struct {
+		name        string
+		tables      map[string]string
+		expected    []string
+		expectedErr error
+	}{
+		{
+			name: "GivenTablesForEachPrefix_ThenColumnsQualified",
+			tables: map[string]string{
+				"":            "account",
+				"alias":       "account_alias",
+				"pets":        "pet",
+				"pets_colour": "colour",
+			},
+			expected: []string{
+				"account.id AS id",
+				"account.nickname AS nickname",
+				"account.address_street AS address_street",
+				"account_alias.alias AS alias",
+				"pet.name AS pets_name",
+				"colour.name AS pets_colour_name",
+				"colour.red AS pets_colour_red",
+			},
+		},
+		{
+			name: "GivenMissingTables_ThenParentTablesUsed",
+			tables: map[string]string{
+				"pets": "pet",
+			},
+			expected: []string{
+				"id AS id",
+				"nickname AS nickname",
+				"address_street AS address_street",
+				"alias AS alias",
+				"pet.name AS pets_name",
+				"pet.colour_name AS pets_colour_name",
+				"pet.colour_red AS pets_colour_red",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Act
+			result, err := Columns[columnsTestAccount](test.tables)
+
+			// Assert
+			assert.Equal(t, test.expectedErr, err)
+			assert.Equal(t, test.expected, result)
+		})
+	}
+}
+
+func TestColumns_WithNameMapper(t *testing.T) {
+	type pet struct {
+		PetName string
+	}
+
+	type account struct {
+		ID   int
+		Pets []pet
+	}
+
+	// Act
+	result, err := Columns[account](map[string]string{"": "a", "pets": "p"}, WithNameMapper(SnakeCase))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a.id AS id", "p.pet_name AS pets_pet_name"}, result)
+}
+
+func TestColumns_InvalidType(t *testing.T) {
+	// Act
+	result, err := Columns[int](nil)
+
+	// Assert
+	assert.Equal(t, fmt.Errorf("input type (int) must be of type struct or pointer to struct"), err)
+	assert.Nil(t, result)
+}
+
+func TestValidateColumnCases(t *testing.T) {
+	type colour struct {
+		Red int `sql:"red"`
+	}
+
+	type clashing struct {
+		Name  string `sql:"Name"`
+		Alias string `sql:"name"`
+	}
+
+	type nestedClash struct {
+		Colour    colour `sql:"colour"`
+		ColourRed int    `sql:"COLOUR_RED"`
+	}
+
+	type distinct struct {
+		Name   string `sql:"name"`
+		Colour colour `sql:"colour"`
+	}
+
+	tests := []struct {
+		name        string
+		input       interface{}
+		opts        []Option
+		expectedErr error
+	}{
+		{
+			name:  "GivenCaseSensitiveColumns_ThenNoError",
+			input: clashing{},
+		},
+		{
+			name:  "GivenDistinctColumns_ThenNoError",
+			input: distinct{},
+			opts:  []Option{WithCaseInsensitiveColumns()},
+		},
+		{
+			name:        "GivenColumnsDifferingByCase_ThenErrorReturned",
+			input:       clashing{},
+			opts:        []Option{WithCaseInsensitiveColumns()},
+			expectedErr: fmt.Errorf("goscanql: fields Name and Alias are mapped to columns that only differ by case (Name and name)"),
+		},
+		{
+			name:        "GivenNestedColumnsDifferingByCase_ThenErrorReturned",
+			input:       nestedClash{},
+			opts:        []Option{WithCaseInsensitiveColumns()},
+			expectedErr: fmt.Errorf("goscanql: fields Red and ColourRed are mapped to columns that only differ by case (colour_red and COLOUR_RED)"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Act
+			err := validateType(test.input, newOptions(test.opts))
+
+			// Assert
+			assert.Equal(t, test.expectedErr, err)
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package goscanql
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// converter converts a value read from a column (src) into a value of the type that it was
+// registered for.
+type converter func(src interface{}) (reflect.Value, error)
+
+var (
+	// textUnmarshalerType is the type of the encoding.TextUnmarshaler interface.
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+	// binaryUnmarshalerType is the type of the encoding.BinaryUnmarshaler interface.
+	binaryUnmarshalerType = reflect.TypeOf((*encoding.BinaryUnmarshaler)(nil)).Elem()
+
+	// convertersMu guards globalConverters.
+	convertersMu sync.RWMutex
+
+	// globalConverters holds the converters registered by RegisterConverter, keyed by the type
+	// that they convert to.
+	globalConverters = map[reflect.Type]converter{}
+)
+
+// newConverter wraps the provided conversion function (fn) as a converter.
+func newConverter[T any](fn func(src any) (T, error)) converter {
+	return func(src interface{}) (reflect.Value, error) {
+		v, err := fn(src)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		return reflect.ValueOf(&v).Elem(), nil
+	}
+}
+
+// RegisterConverter registers the provided function (fn) as the converter of T for every scan,
+// so that fields of type T (or pointers to T) can be scanned without T implementing Scanner, e.g.
+//
+//	goscanql.RegisterConverter(func(src any) (decimal.Decimal, error) {
+//		return decimal.NewFromString(fmt.Sprint(src))
+//	})
+//
+// The converter receives each value of the field's column as it is read from the row (including
+// nil where the column is null, unless the field is a pointer, in which case it is set to nil).
+// Fields of type T are treated as single values (even where T is a struct or a slice), and are
+// identified by the output of their MarshalText, MarshalBinary or String method (whichever they
+// have first), or their Go representation otherwise.
+//
+// A converter provided by WithConverter takes precedence over one registered for the same type.
+func RegisterConverter[T any](fn func(src any) (T, error)) {
+	convertersMu.Lock()
+	defer convertersMu.Unlock()
+
+	globalConverters[reflect.TypeOf((*T)(nil)).Elem()] = newConverter(fn)
+}
+
+// WithConverter returns an Option that will use the provided function (fn) as the converter of T,
+// in the same way as RegisterConverter but only for the scans that it is provided to.
+func WithConverter[T any](fn func(src any) (T, error)) Option {
+	return func(o *options) {
+		if o.converters == nil {
+			o.converters = make(map[reflect.Type]converter)
+		}
+
+		o.converters[reflect.TypeOf((*T)(nil)).Elem()] = newConverter(fn)
+	}
+}
+
+// converterFor returns the converter of the provided (non-pointer) type (t), if one has been
+// provided (either by WithConverter or RegisterConverter), if the type is time.Time and time
+// options have been provided, or if the type can unmarshal itself.
+func (o *options) converterFor(t reflect.Type) (converter, bool) {
+	if o != nil {
+		if c, ok := o.converters[t]; ok {
+			return c, true
+		}
+	}
+
+	if t == timeType {
+		if c, ok := o.timeConverter(); ok {
+			return c, true
+		}
+	}
+
+	convertersMu.RLock()
+	c, ok := globalConverters[t]
+	convertersMu.RUnlock()
+
+	if ok {
+		return c, true
+	}
+
+	return unmarshalerConverter(t)
+}
+
+// unmarshalerConverter returns a converter for the provided (non-pointer) type (t) where it
+// implements encoding.TextUnmarshaler or encoding.BinaryUnmarshaler (preferring the former), so
+// that the values of its column are decoded by the type itself. Types that implement sql.Scanner
+// (including goscanql's Scanners) and time.Time are instead scanned as they always have been.
+func unmarshalerConverter(t reflect.Type) (converter, bool) {
+	pt := reflect.PointerTo(t)
+
+	if t == timeType || pt.Implements(sqlScannerType) {
+		return nil, false
+	}
+
+	switch {
+	case pt.Implements(textUnmarshalerType):
+		return func(src interface{}) (reflect.Value, error) {
+			v := reflect.New(t)
+
+			b, ok := unmarshalerBytes(src)
+			if !ok {
+				return v.Elem(), assignReflect(v.Elem(), src)
+			}
+
+			err := v.Interface().(encoding.TextUnmarshaler).UnmarshalText(b)
+			return v.Elem(), err
+		}, true
+
+	case pt.Implements(binaryUnmarshalerType):
+		return func(src interface{}) (reflect.Value, error) {
+			v := reflect.New(t)
+
+			b, ok := unmarshalerBytes(src)
+			if !ok {
+				return v.Elem(), assignReflect(v.Elem(), src)
+			}
+
+			err := v.Interface().(encoding.BinaryUnmarshaler).UnmarshalBinary(b)
+			return v.Elem(), err
+		}, true
+	}
+
+	return nil, false
+}
+
+// unmarshalerBytes returns the bytes that the provided value (src) of a column is unmarshaled
+// from, and whether it is unmarshaled at all. Only []byte and string values are unmarshaled, as
+// any other value (e.g. an int64, for a type such as slog.Level) isn't in the encoded form of the
+// type, so is instead assigned to it (in the same way as the values of a RowSource).
+func unmarshalerBytes(src interface{}) ([]byte, bool) {
+	switch s := src.(type) {
+	case []byte:
+		return s, true
+	case string:
+		return []byte(s), true
+	}
+
+	return nil, false
+}
+
+// hasConverter returns true if the provided type (t), or the type that it points to, has a
+// converter.
+func (o *options) hasConverter(t reflect.Type) bool {
+	_, ok := o.converterFor(getPointerRootType(t))
+	return ok
+}
+
+// converterScanner is the destination of a column whose field has a converter, converting each of
+// its values before setting them on the field.
+type converterScanner struct {
+
+	// field is the (settable) field that the converted values are set on.
+	field reflect.Value
+
+	// convert is the converter of the field's type.
+	convert converter
+}
+
+// Scan converts the provided value (src) and sets the result on the field, setting pointer fields
+// to nil where src is nil.
+func (c *converterScanner) Scan(src interface{}) error {
+	if src == nil && c.field.Kind() == reflect.Pointer {
+		c.field.Set(reflect.Zero(c.field.Type()))
+		return nil
+	}
+
+	// the bytes of a row may be reused by the driver once the row has been scanned
+	if b, ok := src.([]byte); ok {
+		src = append([]byte{}, b...)
+	}
+
+	v, err := c.convert(src)
+	if err != nil {
+		return err
+	}
+
+	instantiateValue(c.field)[0].Set(v)
+	return nil
+}
+
+// id returns the identity of the field's current value, which is used in place of the value
+// itself when the entity is hashed. The value is identified by the output of the first of
+// MarshalText, MarshalBinary and String that it has (or its Go representation otherwise).
+func (c *converterScanner) id() string {
+	v := c.field
+
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return "nil"
+		}
+
+		v = v.Elem()
+	}
+
+	if t, ok := v.Interface().(time.Time); ok {
+		return timeID(t)
+	}
+
+	// methods of the pointer (e.g. String of url.URL) are included where the value is addressable
+	values := []interface{}{v.Interface()}
+	if v.CanAddr() {
+		values = append(values, v.Addr().Interface())
+	}
+
+	for _, value := range values {
+		if marshaler, ok := value.(encoding.TextMarshaler); ok {
+			if b, err := marshaler.MarshalText(); err == nil {
+				return string(b)
+			}
+		}
+
+		if marshaler, ok := value.(encoding.BinaryMarshaler); ok {
+			if b, err := marshaler.MarshalBinary(); err == nil {
+				return string(b)
+			}
+		}
+	}
+
+	for _, value := range values {
+		if stringer, ok := value.(fmt.Stringer); ok {
+			return stringer.String()
+		}
+	}
+
+	return fmt.Sprintf("%#v", v.Interface())
+}
+
+// addConverter will record the provided converterScanner as the destination of the column of the
+// field with the provided name.
+func (f *fields) addConverter(name string, c *converterScanner) {
+	if f.converters == nil {
+		f.converters = make(map[string]*converterScanner)
+	}
+
+	f.converters[name] = c
+}
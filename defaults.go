@@ -0,0 +1,136 @@
+package goscanql
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// fieldDefault holds the default value of a field, which is used where the field's column is null.
+type fieldDefault struct {
+
+	// raw is the default value, as provided by the default tag option.
+	raw string
+
+	// field is the name that the field is known by in Go, e.g. User.Status.
+	field string
+}
+
+// validateDefaults ensures that the default value of each field of the provided type (t) that has
+// one can be scanned into the field, so that a bad default is reported before any rows are
+// scanned (rather than only once a null is scanned).
+func validateDefaults(t reflect.Type, o *options) error {
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	for _, sf := range structFields(t, o) {
+		raw, ok := sf.tag.get(defaultTagOption)
+		if !ok {
+			continue
+		}
+
+		err := validateDefault(raw, sf, o)
+		if err != nil {
+			return fmt.Errorf("goscanql: invalid default value %q for field %s.%s: %w", raw, t.String(), sf.field.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// validateDefault ensures that the provided raw default value can be scanned into the provided
+// field, by scanning it into a new value of the field's type (or converting it, where the type has
+// a converter).
+func validateDefault(raw string, sf structField, o *options) error {
+	if c, ok := o.converterFor(getPointerRootType(sf.field.Type)); ok {
+		_, err := c(raw)
+		return err
+	}
+
+	v := reflect.New(getPointerRootType(sf.field.Type))
+
+	switch sf.kind {
+	case scannerKind:
+		return assignValue(asScanner(v), raw)
+
+	case valueKind:
+		return assignValue(v.Interface(), raw)
+	}
+
+	return fmt.Errorf("default values aren't supported for relationships")
+}
+
+// addDefault will record the provided raw default value for the field with the provided name
+// (known in Go by the provided goName).
+func (f *fields) addDefault(name, goName, raw string) {
+	if f.defaults == nil {
+		f.defaults = make(map[string]fieldDefault)
+	}
+
+	f.defaults[name] = fieldDefault{raw: raw, field: goName}
+}
+
+// nullDefaults returns the reference (or Scanner) of each field of the fields (and its children)
+// that has a default value and whose column held null, keyed by the full name of the column. Only
+// the present columns (keyed by their columnKey) of entities that aren't nil are included.
+func (f *fields) nullDefaults(present map[string]bool) map[string]defaultTarget {
+	m := make(map[string]defaultTarget)
+
+	f.crawlFields(func(prefix string, fi *fields) bool {
+		if fi.isNil() {
+			return true
+		}
+
+		for name, d := range fi.defaults {
+			column := buildReferenceName(prefix, name)
+
+			if !fi.nullFields[name].isNil || !present[f.opts.columnKey(column)] {
+				continue
+			}
+
+			target := defaultTarget{fieldDefault: d, reference: fi.references[name]}
+			if scanner, ok := fi.scannerReferences[name]; ok {
+				target.reference = scanner
+			}
+
+			if c, ok := fi.converters[name]; ok {
+				target.reference = c
+			}
+
+			m[column] = target
+		}
+
+		return false
+	})
+
+	return m
+}
+
+// defaultTarget holds the default value of a field, and the reference (or Scanner) of the field
+// that it is to be set on.
+type defaultTarget struct {
+	fieldDefault
+
+	// reference is a pointer to the field, or the field's Scanner (or converterScanner).
+	reference interface{}
+}
+
+// applyDefaults will set each of the provided targets (keyed by the name of their column) to its
+// default value, returning a ScanError where a Scanner rejects its default.
+func applyDefaults(targets map[string]defaultTarget) error {
+	for column, target := range targets {
+		err := target.apply()
+		if err != nil {
+			return &ScanError{Column: column, Field: target.field, Err: err}
+		}
+	}
+
+	return nil
+}
+
+// apply will set the field of the defaultTarget to its default value, in the same way that the
+// values of a RowSource are assigned, i.e. a Scanner receives the value through its Scan method,
+// and the value is otherwise parsed into the type of the field (allocating any pointers).
+func (d defaultTarget) apply() error {
+	return assignValue(d.reference, d.raw)
+}
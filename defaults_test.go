@@ -0,0 +1,165 @@
+package goscanql
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateDefaults(t *testing.T) {
+	type pet struct {
+		Name string `sql:"name,default=unnamed"`
+	}
+
+	tests := []struct {
+		name        string
+		input       interface{}
+		expectedErr error
+	}{
+		{
+			name: "GivenValidDefaults_ThenNoError",
+			input: struct {
+				Retries  int        `sql:"retries,default=3"`
+				Nickname NullString `sql:"nickname,default=none"`
+				Age      NullInt64  `sql:"age,default=30"`
+				Joined   time.Time  `sql:"joined,default=2023-04-01"`
+				Pets     []pet      `sql:"pets"`
+			}{},
+		},
+		{
+			name: "GivenInvalidDefault_ThenError",
+			input: struct {
+				Retries int `sql:"retries,default=three"`
+			}{},
+			expectedErr: fmt.Errorf("goscanql: invalid default value \"three\" for field struct { Retries int \"sql:\\\"retries,default=three\\\"\" }.Retries: strconv.ParseInt: parsing \"three\": invalid syntax"),
+		},
+		{
+			name: "GivenInvalidScannerDefault_ThenError",
+			input: struct {
+				Age NullInt64 `sql:"age,default=old"`
+			}{},
+			expectedErr: fmt.Errorf("goscanql: invalid default value \"old\" for field struct { Age goscanql.NullInt64 \"sql:\\\"age,default=old\\\"\" }.Age: strconv.ParseInt: parsing \"old\": invalid syntax"),
+		},
+		{
+			name: "GivenDefaultOnRelationship_ThenError",
+			input: struct {
+				Pets []pet `sql:"pets,default=none"`
+			}{},
+			expectedErr: fmt.Errorf("goscanql: invalid default value \"none\" for field struct { Pets []goscanql.pet \"sql:\\\"pets,default=none\\\"\" }.Pets: default values aren't supported for relationships"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Act
+			err := validateType(test.input, nil)
+
+			// Assert
+			assert.Equal(t, test.expectedErr, errorOrNil(err))
+		})
+	}
+}
+
+// errorOrNil returns the message of the provided error as a new error (or nil), so that errors
+// can be compared regardless of their wrapping.
+func errorOrNil(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return fmt.Errorf("%s", err)
+}
+
+func Test_RowsToStructsWithDefaults(t *testing.T) {
+	type pet struct {
+		Name string `sql:"name"`
+		Age  int    `sql:"age,default=1"`
+	}
+
+	type account struct {
+		ID       int        `sql:"id"`
+		Status   string     `sql:"status,default=active,notnull"`
+		Retries  int        `sql:"retries,default=3"`
+		Nickname NullString `sql:"nickname,default=none"`
+		Pets     []pet      `sql:"pets"`
+	}
+
+	// Arrange
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		panic(err)
+	}
+
+	inputRows := sqlmock.NewRows([]string{"id", "status", "retries", "nickname", "pets_name", "pets_age"})
+
+	for _, row := range [][]driver.Value{
+		{1, nil, nil, nil, "Bandit", nil},
+		{1, nil, nil, nil, "Babou", 4},
+		{2, "locked", 0, "Duchess", nil, nil},
+	} {
+		inputRows.AddRow(row...)
+	}
+
+	mock.ExpectQuery("SELECT").WillReturnRows(inputRows)
+
+	rows, err := db.Query("SELECT")
+	if err != nil {
+		panic(err)
+	}
+
+	expected := []account{
+		{
+			ID:       1,
+			Status:   "active",
+			Retries:  3,
+			Nickname: NullString{String: "none", Valid: true},
+			Pets:     []pet{{Name: "Bandit", Age: 1}, {Name: "Babou", Age: 4}},
+		},
+		{
+			ID:       2,
+			Status:   "locked",
+			Retries:  0,
+			Nickname: NullString{String: "Duchess", Valid: true},
+		},
+	}
+
+	// Act
+	result, err := RowsToStructs[account](rows)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, expected, result)
+}
+
+func Test_RowsToStructsWithPointerDefault(t *testing.T) {
+	type account struct {
+		ID    int  `sql:"id"`
+		Limit *int `sql:"limit,default=10"`
+	}
+
+	// Arrange
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		panic(err)
+	}
+
+	mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"id", "limit"}).AddRow(1, nil))
+
+	rows, err := db.Query("SELECT")
+	if err != nil {
+		panic(err)
+	}
+
+	limit := 10
+
+	// Act
+	result, err := RowsToStructs[account](rows)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []account{{ID: 1, Limit: &limit}}, result)
+}
@@ -0,0 +1,80 @@
+package goscanql
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Dialect represents the flavour of SQL spoken by a database, and is used by goscanql to build
+// statements that the database will understand.
+type Dialect interface {
+
+	// Quote returns the provided identifier (e.g. a table or column name) quoted so that it can
+	// be safely used in a statement.
+	Quote(identifier string) string
+
+	// Placeholder returns the placeholder for the nth (starting from 1) argument of a statement.
+	Placeholder(n int) string
+}
+
+var (
+	// Postgres is the Dialect of PostgreSQL.
+	Postgres Dialect = postgresDialect{}
+
+	// MySQL is the Dialect of MySQL (and MariaDB).
+	MySQL Dialect = mysqlDialect{}
+
+	// SQLite is the Dialect of SQLite.
+	SQLite Dialect = sqliteDialect{}
+)
+
+// postgresDialect implements Dialect for PostgreSQL.
+type postgresDialect struct{}
+
+func (postgresDialect) Quote(identifier string) string {
+	return quoteIdentifier(identifier, `"`)
+}
+
+func (postgresDialect) Placeholder(n int) string {
+	return "$" + strconv.Itoa(n)
+}
+
+// mysqlDialect implements Dialect for MySQL.
+type mysqlDialect struct{}
+
+func (mysqlDialect) Quote(identifier string) string {
+	return quoteIdentifier(identifier, "`")
+}
+
+func (mysqlDialect) Placeholder(_ int) string {
+	return "?"
+}
+
+// sqliteDialect implements Dialect for SQLite.
+type sqliteDialect struct{}
+
+func (sqliteDialect) Quote(identifier string) string {
+	return quoteIdentifier(identifier, `"`)
+}
+
+func (sqliteDialect) Placeholder(_ int) string {
+	return "?"
+}
+
+// defaultDialect implements Dialect for where no Dialect has been provided. Identifiers are left
+// unquoted and arguments use ? as their placeholder.
+type defaultDialect struct{}
+
+func (defaultDialect) Quote(identifier string) string {
+	return identifier
+}
+
+func (defaultDialect) Placeholder(_ int) string {
+	return "?"
+}
+
+// quoteIdentifier wraps the provided identifier in the provided quote, escaping any occurrences of
+// the quote within the identifier by doubling them.
+func quoteIdentifier(identifier, quote string) string {
+	return quote + strings.ReplaceAll(identifier, quote, quote+quote) + quote
+}
@@ -0,0 +1,57 @@
+package goscanql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDialect_Quote(t *testing.T) {
+	tests := []struct {
+		name     string
+		dialect  Dialect
+		input    string
+		expected string
+	}{
+		{
+			name:     "Postgres",
+			dialect:  Postgres,
+			input:    "account",
+			expected: `"account"`,
+		},
+		{
+			name:     "PostgresEscaped",
+			dialect:  Postgres,
+			input:    `acc"ount`,
+			expected: `"acc""ount"`,
+		},
+		{
+			name:     "MySQL",
+			dialect:  MySQL,
+			input:    "account",
+			expected: "`account`",
+		},
+		{
+			name:     "MySQLEscaped",
+			dialect:  MySQL,
+			input:    "acc`ount",
+			expected: "`acc``ount`",
+		},
+		{
+			name:     "SQLite",
+			dialect:  SQLite,
+			input:    "account",
+			expected: `"account"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Act
+			result := test.dialect.Quote(test.input)
+
+			// Assert
+			assert.Equal(t, test.expected, result)
+		})
+	}
+}
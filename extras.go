@@ -0,0 +1,138 @@
+package goscanql
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// extrasType is the type of a field tagged with the extra option.
+var extrasType = reflect.TypeOf(map[string]interface{}{})
+
+// validateExtras ensures that each of the fields of the provided type (t) that are tagged with the
+// extra option are of type map[string]interface{}, and that there is no more than one of them.
+func validateExtras(t reflect.Type, o *options) error {
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	found := ""
+
+	for i := 0; i < t.NumField(); i++ {
+		if !o.isExtra(t, i) {
+			continue
+		}
+
+		field := t.Field(i)
+
+		if field.Type != extrasType {
+			return fmt.Errorf("goscanql: extra field %s.%s must be of type %s (not %s)", t.String(), field.Name, extrasType.String(), field.Type.String())
+		}
+
+		if found != "" {
+			return fmt.Errorf("goscanql: %s has more than one extra field (%s and %s)", t.String(), found, field.Name)
+		}
+
+		found = field.Name
+	}
+
+	return nil
+}
+
+// extraScanner is the destination of a column that is collected by an extra field, setting its
+// values in the field's map under the provided name.
+type extraScanner struct {
+
+	// extras is the (settable) extra field, which is allocated by the first value that is set.
+	extras *map[string]interface{}
+
+	// name is the name of the column relative to the entity of the extra field.
+	name string
+}
+
+// Scan sets the provided value (src) in the map of the extra field.
+func (s *extraScanner) Scan(src interface{}) error {
+	// the bytes of a row may be reused by the driver once the row has been scanned
+	if b, ok := src.([]byte); ok {
+		src = append([]byte{}, b...)
+	}
+
+	if *s.extras == nil {
+		*s.extras = make(map[string]interface{})
+	}
+
+	(*s.extras)[s.name] = src
+	return nil
+}
+
+// extraScanners returns the destination of each of the provided columns that isn't mapped to a
+// field (i.e. isn't one of the provided mapped columns), and is collected by an extra field,
+// keyed by the full name of the column.
+//
+// A column is collected by the extra field of the entity at the deepest nesting level whose prefix
+// it has (e.g. pets_age by the Pet of a []Pet tagged pets), unless that entity is nil or has no
+// extra field.
+func (f *fields) extraScanners(columns []string, mapped map[string]*nullBytes) map[string]*extraScanner {
+	keyed := make(map[string]bool, len(mapped))
+	for name := range mapped {
+		keyed[f.opts.columnKey(name)] = true
+	}
+
+	prefixes := make(map[string]*fields)
+	f.crawlFields(func(prefix string, fi *fields) bool {
+		prefixes[f.opts.columnKey(prefix)] = fi
+		return false
+	})
+
+	scanners := make(map[string]*extraScanner)
+
+	for _, column := range columns {
+		key := f.opts.columnKey(column)
+		if keyed[key] {
+			continue
+		}
+
+		prefix, owner := "", f
+		for p, fi := range prefixes {
+			if len(p) > len(prefix) && strings.HasPrefix(key, p+"_") {
+				prefix, owner = p, fi
+			}
+		}
+
+		if owner.extras == nil || owner.isNil() {
+			continue
+		}
+
+		name := column
+		if prefix != "" {
+			name = column[len(prefix)+1:]
+		}
+
+		scanners[column] = &extraScanner{extras: owner.extras, name: name}
+	}
+
+	return scanners
+}
+
+// getExtrasPrint returns the "fingerprint" of the extra field of the fields (if it has one),
+// naming each of its columns with the provided prefix.
+func (f *fields) getExtrasPrint(prefix string) []byte {
+	if f.extras == nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(*f.extras))
+	for name := range *f.extras {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	print := make([]byte, 0)
+	for _, name := range names {
+		print = append(print, fmt.Sprintf("{%s:%#v}", buildReferenceName(prefix, name), (*f.extras)[name])...)
+	}
+
+	return print
+}
@@ -0,0 +1,159 @@
+package goscanql
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateExtras(t *testing.T) {
+	type pet struct {
+		Name  string                 `sql:"name"`
+		Extra map[string]interface{} `sql:",extra"`
+	}
+
+	tests := []struct {
+		name        string
+		input       interface{}
+		expectedErr error
+	}{
+		{
+			name: "GivenValidExtras_ThenNoError",
+			input: struct {
+				ID    int            `sql:"id"`
+				Extra map[string]any `sql:",extra"`
+				Pets  []pet          `sql:"pets"`
+			}{},
+		},
+		{
+			name: "GivenExtraOfWrongType_ThenError",
+			input: struct {
+				Extra map[string]string `sql:",extra"`
+			}{},
+			expectedErr: fmt.Errorf("goscanql: extra field struct { Extra map[string]string \"sql:\\\",extra\\\"\" }.Extra must be of type map[string]interface {} (not map[string]string)"),
+		},
+		{
+			name: "GivenMultipleExtras_ThenError",
+			input: struct {
+				Extra map[string]any `sql:",extra"`
+				Other map[string]any `sql:"other,extra"`
+			}{},
+			expectedErr: fmt.Errorf("goscanql: struct { Extra map[string]interface {} \"sql:\\\",extra\\\"\"; Other map[string]interface {} \"sql:\\\"other,extra\\\"\" } has more than one extra field (Extra and Other)"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Act
+			err := validateType(test.input, nil)
+
+			// Assert
+			assert.Equal(t, test.expectedErr, errorOrNil(err))
+		})
+	}
+}
+
+func Test_RowsToStructsWithExtras(t *testing.T) {
+	type pet struct {
+		Name  string         `sql:"name"`
+		Extra map[string]any `sql:",extra"`
+	}
+
+	type report struct {
+		Region string         `sql:"region"`
+		Extra  map[string]any `sql:",extra"`
+		Pets   []pet          `sql:"pets"`
+	}
+
+	// Arrange
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		panic(err)
+	}
+
+	inputRows := sqlmock.NewRows([]string{"region", "total", "Avg_Age", "pets_name", "pets_age"})
+
+	for _, row := range [][]driver.Value{
+		{"north", 2, []byte("4.5"), "Rex", 3},
+		{"north", 2, []byte("4.5"), "Tom", 6},
+		{"north", 3, nil, "Rex", 3},
+		{"south", 0, nil, nil, nil},
+	} {
+		inputRows.AddRow(row...)
+	}
+
+	mock.ExpectQuery("SELECT").WillReturnRows(inputRows)
+
+	rows, err := db.Query("SELECT")
+	if err != nil {
+		panic(err)
+	}
+
+	expected := []report{
+		{
+			Region: "north",
+			Extra:  map[string]any{"total": int64(2), "Avg_Age": []byte("4.5")},
+			Pets: []pet{
+				{Name: "Rex", Extra: map[string]any{"age": int64(3)}},
+				{Name: "Tom", Extra: map[string]any{"age": int64(6)}},
+			},
+		},
+		{
+			Region: "north",
+			Extra:  map[string]any{"total": int64(3), "Avg_Age": nil},
+			Pets: []pet{
+				{Name: "Rex", Extra: map[string]any{"age": int64(3)}},
+			},
+		},
+		{
+			Region: "south",
+			Extra:  map[string]any{"total": int64(0), "Avg_Age": nil},
+		},
+	}
+
+	// Act
+	result, err := RowsToStructs[report](rows)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, expected, result)
+}
+
+func Test_RowsToStructsWithExtrasAndKey(t *testing.T) {
+	type report struct {
+		Region string
+		Extra  map[string]any
+	}
+
+	mapping := NewMapping().Map(report{}, TypeMapping{
+		"Region": {Column: "region", Key: true},
+		"Extra":  {Options: []string{"extra"}},
+	})
+
+	// Arrange
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		panic(err)
+	}
+
+	inputRows := sqlmock.NewRows([]string{"region", "total"}).
+		AddRow("north", 2).
+		AddRow("north", 3)
+
+	mock.ExpectQuery("SELECT").WillReturnRows(inputRows)
+
+	rows, err := db.Query("SELECT")
+	if err != nil {
+		panic(err)
+	}
+
+	// Act
+	result, err := RowsToStructs[report](rows, WithMapping(mapping))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []report{{Region: "north", Extra: map[string]any{"total": int64(2)}}}, result)
+}
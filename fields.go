@@ -4,7 +4,6 @@ import (
 	"crypto/sha1"
 	"fmt"
 	"reflect"
-	"strings"
 	"time"
 )
 
@@ -34,6 +33,9 @@ type fields struct {
 	// obj is a reference (pointer) to the struct that this fields fields belong to.
 	obj interface{}
 
+	// opts holds the options that the fields was created with (nil if defaults are to be used).
+	opts *options
+
 	// orderedFieldNames maintains the field names in the order of which they were added
 	// to facilitate reliable hashing when comparing fields entities.
 	orderedFieldNames []string
@@ -65,6 +67,38 @@ type fields struct {
 	// oneToManys holds all child structs of the fields entity that are maintained as a
 	// one-to-many relationship (meaning the sub-struct is contained within a slice).
 	oneToManys map[string]*fields
+
+	// keys holds the names of the fields (and scanners) that identify the entity, where any are
+	// marked as keys by the Mapping (nil otherwise).
+	keys map[string]bool
+
+	// notNull holds the names of the fields that must not be scanned from a null column, mapped to
+	// the names that they are known by in Go, e.g. User.Age (nil where there are none).
+	notNull map[string]string
+
+	// defaults holds the default value of each field that has one, to be used where the field's
+	// column is null (nil where there are none).
+	defaults map[string]fieldDefault
+
+	// converters holds the destination of each field whose type has a converter, which converts
+	// the field's values before setting them (nil where there are none).
+	converters map[string]*converterScanner
+
+	// presence is the Presence of the entity that the fields represents, to be filled in once the
+	// entity has been scanned (nil where the entity has none).
+	presence *Presence
+
+	// extras is the field of the entity that collects the columns that aren't mapped to any other
+	// field (nil where the entity has none).
+	extras *map[string]interface{}
+
+	// multiset determines whether duplicates of this fields (as a one-to-many child) should be
+	// preserved rather than merged into a single entity.
+	multiset bool
+
+	// features holds the optional features used by the fields and its children, so that the passes
+	// of a scan that support them can be skipped where they aren't used.
+	features features
 }
 
 // addNewChild will create a new fields entity and add it to the current fields as a child
@@ -76,25 +110,20 @@ func (f *fields) addNewChild(name string, obj interface{}) error {
 	rv := reflect.ValueOf(obj)
 
 	// create new fields instance
-	child, err := newFields(obj)
+	child, err := newFields(obj, f.opts)
 	if err != nil {
 		return err
 	}
 
 	// ensure that child with name doesn't already exist
-	collisionErr := fmt.Errorf("child already exists with name \"%s\"", name)
+	_, oneToOne := f.oneToOnes[name]
+	_, oneToMany := f.oneToManys[name]
 
-	for childName := range f.oneToOnes {
-		if childName == name {
-			return collisionErr
-		}
+	if oneToOne || oneToMany {
+		return fmt.Errorf("child already exists with name \"%s\"", name)
 	}
 
-	for childName := range f.oneToManys {
-		if childName == name {
-			return collisionErr
-		}
-	}
+	f.features |= child.features
 
 	// add child to appropriate relationship map of fields
 	if rv.Elem().Kind() == reflect.Slice {
@@ -151,6 +180,36 @@ func (f *fields) addScanner(name string, value Scanner) error {
 	return nil
 }
 
+// addKey will record the field with the provided name as one that identifies the entity, if its
+// plan (fp) says that it does.
+func (f *fields) addKey(name string, fp fieldPlan) {
+	if !fp.key {
+		return
+	}
+
+	if f.keys == nil {
+		f.keys = make(map[string]bool)
+	}
+
+	f.keys[name] = true
+}
+
+// addNotNull will record the field with the provided name (known in Go by the provided goName) as
+// one that must not be scanned from a null column.
+func (f *fields) addNotNull(name, goName string) {
+	if f.notNull == nil {
+		f.notNull = make(map[string]string)
+	}
+
+	f.notNull[name] = goName
+}
+
+// isIdentifying returns true if the field with the provided name identifies the entity, which is
+// the case for every field unless the entity has key fields.
+func (f *fields) isIdentifying(name string) bool {
+	return len(f.keys) == 0 || f.keys[name]
+}
+
 // getFieldReferences returns a map of all of the fields references (including any child
 // field references).
 func (f *fields) getFieldReferences() map[string]interface{} {
@@ -165,8 +224,19 @@ func (f *fields) getFieldReferences() map[string]interface{} {
 			m[buildReferenceName(prefix, name)] = reference
 		}
 
+		for name, c := range fi.converters {
+			m[buildReferenceName(prefix, name)] = c
+		}
+
 		for name, scanner := range fi.scannerReferences {
 			m[buildReferenceName(prefix, name)] = scanner
+
+			// NullTimes are converted as described by the time options (if there are any)
+			if c, ok := f.opts.timeConverter(); ok {
+				if nt, ok := scanner.(*NullTime); ok {
+					m[buildReferenceName(prefix, name)] = &nullTimeScanner{dest: nt, convert: c}
+				}
+			}
 		}
 
 		return false
@@ -222,17 +292,14 @@ func (f *fields) crawlFieldsWithPrefix(prefix string, fn func(string, *fields) b
 //
 // Prefix: pet, Name: animal := pet_animal
 func buildReferenceName(prefix, name string) string {
-	strs := make([]string, 0)
-
-	if prefix != "" {
-		strs = append(strs, prefix)
-	}
-
-	if name != "" {
-		strs = append(strs, name)
+	switch {
+	case prefix == "":
+		return name
+	case name == "":
+		return prefix
 	}
 
-	return strings.Join(strs, "_")
+	return prefix + "_" + name
 }
 
 // getHash will hash a fields entity so that it can be easily compared to another fields.
@@ -252,17 +319,45 @@ func (f *fields) getBytePrint(prefix string) []byte {
 	print := make([]byte, 0)
 
 	for _, key := range f.orderedFieldNames {
+		if !f.isIdentifying(key) {
+			continue
+		}
+
 		value := f.references[key]
 		strValue := fmt.Sprintf("{%s:%#v}", buildReferenceName(prefix, key), reflect.ValueOf(value).Elem().Interface())
+
+		// times (including those of pointer fields) are identified by their instant, rather than
+		// their location
+		if t, ok := timeOf(value); ok {
+			strValue = fmt.Sprintf("{%s:%s}", buildReferenceName(prefix, key), timeID(t))
+		}
+
+		// values with a converter are identified by their converter
+		if c, ok := f.converters[key]; ok {
+			strValue = fmt.Sprintf("{%s:%s}", buildReferenceName(prefix, key), c.id())
+		}
+
 		print = append(print, []byte(strValue)...)
 	}
 
 	for _, key := range f.orderedScannerNames {
+		if !f.isIdentifying(key) {
+			continue
+		}
+
 		value := f.scannerReferences[key]
 		strValue := fmt.Sprintf("{%s:%s}", buildReferenceName(prefix, key), value.ID())
 		print = append(print, []byte(strValue)...)
 	}
 
+	// where the entity has key fields, neither its extra field nor its one-to-one children
+	// identify it
+	if len(f.keys) > 0 {
+		return print
+	}
+
+	print = append(print, f.getExtrasPrint(prefix)...)
+
 	for _, key := range f.orderedOneToOneNames {
 		child := f.oneToOnes[key]
 		print = append(print, child.getBytePrint(key)...)
@@ -308,35 +403,129 @@ func (f *fields) emptyNilFields() {
 			continue
 		}
 
-		slice := getRootValue(*fieldByTag(tag, getRootValue(reflect.ValueOf(f.obj))))
+		slice := getRootValue(*fieldByTag(tag, getRootValue(reflect.ValueOf(f.obj)), f.opts))
 		slice.Set(reflect.New(slice.Type()).Elem()) // set to empty slice
 	}
 }
 
+// columnSet returns the provided columns as a set, keyed by their columnKey.
+func (f *fields) columnSet(columns []string) map[string]bool {
+	present := make(map[string]bool, len(columns))
+	for _, col := range columns {
+		present[f.opts.columnKey(col)] = true
+	}
+
+	return present
+}
+
+// checkNotNull returns a ScanError if any of the present columns (keyed by their columnKey) held
+// null (once they have been written to the nullFields) for a field that must not be null. The
+// fields of nil entities aren't checked, as their columns being null is how they are known to be
+// nil.
+func (f *fields) checkNotNull(present map[string]bool) error {
+	var err error
+
+	f.crawlFields(func(prefix string, fi *fields) bool {
+		if err != nil || fi.isNil() {
+			return true
+		}
+
+		for _, name := range fi.orderedFieldNames {
+			field, ok := fi.notNull[name]
+			column := buildReferenceName(prefix, name)
+
+			// a null is replaced by the field's default value (if it has one)
+			if _, hasDefault := fi.defaults[name]; hasDefault {
+				continue
+			}
+
+			if ok && fi.nullFields[name].isNil && present[f.opts.columnKey(column)] {
+				err = &ScanError{Column: column, Field: field, Err: ErrUnexpectedNull}
+				return true
+			}
+		}
+
+		return false
+	})
+
+	return err
+}
+
 // scan will attempt to apply the provided scan function to the fields object
 // by providing it with all the field references so that values can be written.
 func (f *fields) scan(columns []string, scan func(...interface{}) error) error {
-	byteRefs := mapFieldsToColumns(columns, f.getNullFieldReferences())
+	nulls := f.getNullFieldReferences()
+	byteRefs := mapFieldsToColumns(columns, nulls, f.opts)
 
 	err := scan(byteRefs...)
 	if err != nil {
 		return err
 	}
 
-	refs := mapFieldsToColumns(columns, f.getFieldReferences())
+	var present map[string]bool
+	if f.features.has(notNullFeature | defaultFeature | presenceFeature) {
+		present = f.columnSet(columns)
+	}
+
+	if f.features.has(notNullFeature) {
+		err = f.checkNotNull(present)
+		if err != nil {
+			return err
+		}
+	}
+
+	references := f.getFieldReferences()
+
+	// null columns whose nulls are recorded by a Presence are left as the zero value of their field
+	if f.features.has(presenceFeature) {
+		for name := range f.presenceColumns() {
+			if nulls[name].isNil {
+				delete(references, name)
+			}
+		}
+	}
+
+	// null columns of fields with a default value are set to the default once the row is scanned
+	var defaults map[string]defaultTarget
+	if f.features.has(defaultFeature) {
+		defaults = f.nullDefaults(present)
+		for name := range defaults {
+			delete(references, name)
+		}
+	}
+
+	// columns that aren't mapped to a field are collected by an extra field (where there is one)
+	if f.features.has(extraFeature) {
+		for name, scanner := range f.extraScanners(columns, nulls) {
+			references[name] = scanner
+		}
+	}
+
+	refs := mapFieldsToColumns(columns, references, f.opts)
 
 	err = scan(refs...)
 	if err != nil {
 		return err
 	}
 
+	err = applyDefaults(defaults)
+	if err != nil {
+		return err
+	}
+
 	f.emptyNilFields()
+
+	if f.features.has(presenceFeature) {
+		f.fillPresence(present)
+	}
+
 	return nil
 }
 
 // newFields is the fields constructor that will process the provided object, and use
-// reflection to map it out and maintain references to the object's fields.
-func newFields(obj interface{}) (*fields, error) {
+// reflection to map it out and maintain references to the object's fields. The provided
+// options (o) may be nil, in which case goscanql's defaults are used.
+func newFields(obj interface{}, o *options) (*fields, error) {
 	// instantiate root of obj to create fields around
 	rva := instantiateAndReturnAll(obj)
 	rv := rva[0]
@@ -363,6 +552,7 @@ func newFields(obj interface{}) (*fields, error) {
 	// create new fields
 	fields := &fields{
 		obj:                  obj,
+		opts:                 o,
 		orderedFieldNames:    make([]string, 0),
 		orderedScannerNames:  make([]string, 0),
 		orderedOneToOneNames: make([]string, 0),
@@ -388,7 +578,20 @@ func (f *fields) initialise(prefix string) error {
 	rva := instantiateAndReturnAll(f.obj)
 
 	rv := rva[0]
-	t := rv.Type()
+	plan := f.opts.planOf(rv.Type())
+
+	f.features |= plan.features
+
+	// if type has a converter (this triggers when initialise is called for a slice value)
+	if plan.converter != nil {
+		err := f.addField(prefix, rv.Addr().Interface())
+		if err != nil {
+			return err
+		}
+
+		f.addConverter(prefix, &converterScanner{field: rv, convert: plan.converter})
+		return nil
+	}
 
 	// if type implements the Scanner interface (this triggers when initialise is called for a slice value)
 	if implementsScanner(reflect.TypeOf(f.obj)) {
@@ -418,64 +621,64 @@ func (f *fields) initialise(prefix string) error {
 		return nil
 	}
 
-	// extract expected fields
-	for i := 0; i < t.NumField(); i++ {
-		fieldType := t.Field(i)
-		fieldValue := rv.Field(i)
-
-		fieldName, ok := fieldType.Tag.Lookup(scanqlTag)
+	if plan.presence >= 0 {
+		f.presence = rv.Field(plan.presence).Addr().Interface().(*Presence)
+	}
 
-		if prefix != "" {
-			fieldName = fmt.Sprintf("%s_%s", prefix, fieldName)
-		}
+	if plan.extras >= 0 {
+		f.extras = rv.Field(plan.extras).Addr().Interface().(*map[string]interface{})
+	}
 
-		// skip if field doesn't have scanql tag
-		if !ok {
-			continue
-		}
+	// extract expected fields
+	for _, fp := range plan.fields {
+		fieldValue := rv.Field(fp.index)
+		fieldName := buildReferenceName(prefix, fp.name)
 
 		fieldValueAll := instantiateAndReturnAll(fieldValue.Addr().Interface())
 		fieldValueRoot := fieldValueAll[0]
 
-		var action func() error
-		scanner := asScanner(fieldValueRoot)
+		var err error
 
-		switch {
+		switch fp.kind {
 		// if field implements Scanner
-		case scanner != nil:
-			action = func() error {
-				return f.addScanner(fieldName, scanner)
+		case scannerKind:
+			f.addKey(fieldName, fp)
+
+			if fp.hasDefault {
+				f.addDefault(fieldName, fp.goName, fp.defaultValue)
 			}
 
-		// if nested struct
-		case fieldValueRoot.Kind() == reflect.Struct:
+			err = f.addScanner(fieldName, asScanner(fieldValueRoot))
 
-			// if struct is not time
-			if _, ok := fieldValueRoot.Interface().(time.Time); ok {
-				action = func() error {
-					return f.addField(fieldName, rv.Field(i).Addr().Interface())
-				}
-				break // break out of switch case
+		// if nested struct, evaluate as part of this struct (as one-to-one relationship)
+		case oneToOneKind:
+			err = f.addNewChild(fieldName, fieldValueAll[len(fieldValueAll)-1].Addr().Interface())
+
+		// if nested slice
+		case oneToManyKind:
+			err = f.addNewChild(fieldName, fieldValueRoot.Addr().Interface())
+			if err == nil {
+				f.oneToManys[fieldName].multiset = fp.multiset
 			}
 
-			// evaluate as part of this struct (as one-to-one relationship)
-			action = func() error {
-				return f.addNewChild(fieldName, fieldValueAll[len(fieldValueAll)-1].Addr().Interface())
+		default:
+			f.addKey(fieldName, fp)
+
+			if fp.hasDefault {
+				f.addDefault(fieldName, fp.goName, fp.defaultValue)
 			}
 
-		// if nested slice
-		case fieldValueRoot.Kind() == reflect.Slice:
-			action = func() error {
-				return f.addNewChild(fieldName, fieldValueRoot.Addr().Interface())
+			if fp.notNull {
+				f.addNotNull(fieldName, fp.goName)
 			}
 
-		default:
-			action = func() error {
-				return f.addField(fieldName, rv.Field(i).Addr().Interface())
+			if fp.converter != nil {
+				f.addConverter(fieldName, &converterScanner{field: fieldValue, convert: fp.converter})
 			}
+
+			err = f.addField(fieldName, fieldValue.Addr().Interface())
 		}
 
-		err := action()
 		if err != nil {
 			return err
 		}
@@ -244,7 +244,7 @@ func TestNewFields(t *testing.T) {
 		msg := fmt.Sprintf("%s: failed", test.name)
 
 		// execute sut
-		result, err := newFields(testInputs[test.name])
+		result, err := newFields(testInputs[test.name], nil)
 
 		// assert value equality between expected and result
 		assert.Equalf(t, test.expected, result, msg)
@@ -790,12 +790,12 @@ func TestBuildReferenceName(t *testing.T) {
 }
 
 func TestGetBytePrint(t *testing.T) {
-	expectedBytePrint := []byte(`{foo:36}{bar:"Hello, World!"}{scanner:}{another_scanner:}{single_child_time:time.Date(1, time.January, 1, 0, 0, 0, 0, time.UTC)}{null_child_time:time.Date(1, time.January, 1, 0, 0, 0, 0, time.UTC)}`)
+	expectedBytePrint := []byte(`{foo:36}{bar:"Hello, World!"}{scanner:}{another_scanner:}{single_child_time:0001-01-01T00:00:00Z}{null_child_time:0001-01-01T00:00:00Z}`)
 	assert.Equalf(t, expectedBytePrint, referenceTestExample.getBytePrint(""), "Get Byte Print Test: failed")
 }
 
 func TestGetHash(t *testing.T) {
-	expectedHash := []byte{87, 52, 237, 215, 223, 186, 202, 75, 79, 182, 214, 206, 45, 250, 62, 135, 31, 127, 190, 176}
+	expectedHash := []byte{70, 194, 234, 164, 58, 8, 50, 149, 116, 239, 244, 241, 134, 43, 205, 34, 2, 59, 27, 228}
 	assert.Equalf(t, string(expectedHash), referenceTestExample.getHash(), "Get Hash Test: failed")
 }
 
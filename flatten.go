@@ -0,0 +1,176 @@
+package goscanql
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+)
+
+// flattenEntity returns the rows that goscanql would read to scan the provided entity (v) of the
+// provided type (t), where each row holds a value for each of the columns of collectColumns (in
+// the same order). One-to-many relationships fan the rows out, with sibling one-to-many
+// relationships producing every combination of their children, just as joining them in SQL
+// would.
+//
+// Where v is nil (e.g. a nil one-to-one relationship) a single row of nil values is returned, as
+// is the case for a one-to-many relationship with no children.
+func flattenEntity(t reflect.Type, v reflect.Value, prefix string, o *options) ([][]interface{}, error) {
+	rows := [][]interface{}{{}}
+	v = indirectValue(v)
+
+	for _, field := range structFields(t, o) {
+		name := buildReferenceName(prefix, field.tag.name)
+
+		var fieldValue reflect.Value
+		if v.IsValid() {
+			fieldValue = v.FieldByIndex(field.field.Index)
+		}
+
+		var children [][]interface{}
+		var err error
+
+		switch field.kind {
+		case oneToOneKind:
+			children, err = flattenEntity(field.elemType(), fieldValue, name, o)
+
+		case oneToManyKind:
+			children, err = flattenSlice(field.elemType(), fieldValue, name, o)
+
+		default:
+			children, err = flattenValue(fieldValue, name, o)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		rows = crossRows(rows, children)
+	}
+
+	return rows, nil
+}
+
+// flattenSlice returns the rows of each of the elements of the provided one-to-many relationship
+// (v), whose elements are of the provided type (t). A slice of values (e.g. []string) produces a
+// single column, with a row for each value.
+func flattenSlice(t reflect.Type, v reflect.Value, name string, o *options) ([][]interface{}, error) {
+	v = indirectValue(v)
+	rows := make([][]interface{}, 0)
+
+	if v.IsValid() {
+		for i := 0; i < v.Len(); i++ {
+			var children [][]interface{}
+			var err error
+
+			if kindOf(t, o) == oneToOneKind {
+				children, err = flattenEntity(t, v.Index(i), name, o)
+			} else {
+				children, err = flattenValue(v.Index(i), name, o)
+			}
+
+			if err != nil {
+				return nil, err
+			}
+
+			rows = append(rows, children...)
+		}
+	}
+
+	if len(rows) > 0 {
+		return rows, nil
+	}
+
+	// an empty one-to-many relationship is read from a single row of nils (as a LEFT JOIN with
+	// no matches would produce)
+	if kindOf(t, o) == oneToOneKind {
+		return flattenEntity(t, reflect.Value{}, name, o)
+	}
+
+	return [][]interface{}{{nil}}, nil
+}
+
+// flattenValue returns a single row holding the driver value of the provided field value (v).
+// Scanners that don't implement driver.Valuer can't be written, so are flattened as nil.
+func flattenValue(v reflect.Value, name string, o *options) ([][]interface{}, error) {
+	if v.IsValid() && kindOf(v.Type(), o) == scannerKind && !reflect.PointerTo(getPointerRootType(v.Type())).Implements(valuerType) {
+		return [][]interface{}{{nil}}, nil
+	}
+
+	value, err := driverValue(v)
+	if err == nil {
+		value, err = driver.DefaultParameterConverter.ConvertValue(value)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("goscanql: unable to get value of field %s: %w", name, err)
+	}
+
+	return [][]interface{}{{value}}, nil
+}
+
+// crossRows returns every combination of the provided rows with the provided children, where
+// each child's values are appended to a copy of each row.
+func crossRows(rows, children [][]interface{}) [][]interface{} {
+	result := make([][]interface{}, 0, len(rows)*len(children))
+
+	for _, row := range rows {
+		for _, child := range children {
+			combined := make([]interface{}, 0, len(row)+len(child))
+			combined = append(combined, row...)
+			combined = append(combined, child...)
+
+			result = append(result, combined)
+		}
+	}
+
+	return result
+}
+
+// StructsToRows is the inverse of RowsToStructs, and flattens the provided entities into the
+// columns (and rows) that goscanql would read them from. One-to-one relationships are flattened
+// using their prefixes (e.g. colour_red), and one-to-many relationships fan each entity out into
+// a row per child, with sibling one-to-many relationships producing every combination of their
+// children (the same way that joining them in SQL would).
+//
+// Values are returned as driver values (e.g. an int is returned as an int64), and the Null types
+// (along with any other Scanners that implement driver.Valuer) are returned as the result of
+// Value. Nil entities are skipped.
+func StructsToRows[T any](entities []T, opts ...Option) ([]string, [][]interface{}, error) {
+	var zero T
+
+	o := newOptions(opts)
+
+	err := validateType(zero, o)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	t := reflect.TypeOf(zero)
+
+	columns := collectColumns(t, "", o)
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = c.name
+	}
+
+	rows := make([][]interface{}, 0, len(entities))
+
+	for _, entity := range entities {
+		if !indirectValue(reflect.ValueOf(entity)).IsValid() {
+			continue
+		}
+
+		// take the address of the entity so that its fields are addressable
+		v := reflect.New(t)
+		v.Elem().Set(reflect.ValueOf(entity))
+
+		entityRows, err := flattenEntity(t, v, "", o)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		rows = append(rows, entityRows...)
+	}
+
+	return names, rows, nil
+}
@@ -0,0 +1,97 @@
+package goscanql
+
+import (
+	"database/sql/driver"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+type flattenTestColour struct {
+	Red   int `sql:"red"`
+	Green int `sql:"green"`
+}
+
+type flattenTestPet struct {
+	Name   string             `sql:"name"`
+	Colour *flattenTestColour `sql:"colour"`
+}
+
+type flattenTestAccount struct {
+	ID       int              `sql:"id"`
+	Nickname NullString       `sql:"nickname"`
+	Pets     []flattenTestPet `sql:"pets"`
+	Aliases  []string         `sql:"alias"`
+}
+
+var flattenTestAccounts = []*flattenTestAccount{
+	{
+		ID:       1,
+		Nickname: NullString{String: "Duchess", Valid: true},
+		Pets: []flattenTestPet{
+			{Name: "Babou", Colour: &flattenTestColour{Red: 255, Green: 128}},
+			{Name: "Gustavo"},
+		},
+		Aliases: []string{"Randy", "Archer"},
+	},
+	{
+		ID: 2,
+	},
+}
+
+func TestStructsToRows(t *testing.T) {
+	// Act
+	columns, rows, err := StructsToRows(append(flattenTestAccounts, nil))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"id", "nickname", "pets_name", "pets_colour_red", "pets_colour_green", "alias"}, columns)
+	assert.Equal(t, [][]interface{}{
+		{int64(1), "Duchess", "Babou", int64(255), int64(128), "Randy"},
+		{int64(1), "Duchess", "Babou", int64(255), int64(128), "Archer"},
+		{int64(1), "Duchess", "Gustavo", nil, nil, "Randy"},
+		{int64(1), "Duchess", "Gustavo", nil, nil, "Archer"},
+		{int64(2), nil, nil, nil, nil, nil},
+	}, rows)
+}
+
+func TestStructsToRows_RoundTrip(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		panic(err)
+	}
+
+	columns, rows, err := StructsToRows(flattenTestAccounts)
+	if err != nil {
+		panic(err)
+	}
+
+	mockRows := sqlmock.NewRows(columns)
+	for _, row := range rows {
+		values := make([]driver.Value, len(row))
+		for i, value := range row {
+			values[i] = value
+		}
+
+		mockRows.AddRow(values...)
+	}
+
+	mock.ExpectQuery("SELECT").WillReturnRows(mockRows)
+
+	sqlRows, err := db.Query("SELECT")
+	if err != nil {
+		panic(err)
+	}
+
+	// Act
+	result, err := RowsToStructs[*flattenTestAccount](sqlRows)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []*flattenTestAccount{
+		flattenTestAccounts[0],
+		{ID: 2},
+	}, result)
+}
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"reflect"
 )
 
 const (
@@ -14,13 +15,46 @@ var (
 	// ErrNoStruct is returned by RowsToStruct when the underlying scan is unable to generate a
 	// single struct from the provided sql.Rows.
 	ErrNoStruct = errors.New("goscanql: no structs in result set")
+
+	// ErrUnexpectedNull is the error held by a ScanError where a null value is scanned into a field
+	// that must not be null, e.g. one tagged with the notnull option.
+	ErrUnexpectedNull = errors.New("unexpected null")
 )
 
-func mapFieldsToColumns[T any](cols []string, fields map[string]T) []interface{} {
+// ScanError is returned where the value of a column can't be scanned into the field that it is
+// mapped to.
+type ScanError struct {
+
+	// Column is the name of the column whose value couldn't be scanned.
+	Column string
+
+	// Field is the name of the field that the column is mapped to, e.g. User.Age.
+	Field string
+
+	// Err is the reason that the value couldn't be scanned, e.g. ErrUnexpectedNull.
+	Err error
+}
+
+// Error returns the message of the ScanError.
+func (e *ScanError) Error() string {
+	return fmt.Sprintf("goscanql: can't scan column %s into field %s: %s", e.Column, e.Field, e.Err)
+}
+
+// Unwrap returns the reason that the value couldn't be scanned.
+func (e *ScanError) Unwrap() error {
+	return e.Err
+}
+
+func mapFieldsToColumns[T any](cols []string, fields map[string]T, o *options) []interface{} {
 	values := make([]interface{}, len(cols))
 
+	keyed := make(map[string]T, len(fields))
+	for name, field := range fields {
+		keyed[o.columnKey(name)] = field
+	}
+
 	for i, col := range cols {
-		value, ok := fields[col]
+		value, ok := keyed[o.columnKey(col)]
 		if !ok {
 			values[i] = &[]byte{}
 			continue
@@ -32,43 +66,86 @@ func mapFieldsToColumns[T any](cols []string, fields map[string]T) []interface{}
 	return values
 }
 
-func scanRows[T any](rows *sql.Rows) ([]T, error) {
+func scanRows[T any](rows *sql.Rows, o *options) ([]T, error) {
+	cols, err := rowsColumns(rows, reflect.TypeOf((*T)(nil)).Elem(), o)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanAll[T](cols, rows.Next, rows.Scan, o)
+}
+
+// scanAll reads each row with the provided next and scan functions (which behave as those of
+// *sql.Rows do), and aggregates them into a slice of Ts (the provided type).
+func scanAll[T any](cols []string, next func() bool, scan func(...interface{}) error, o *options) ([]T, error) {
 	var zero T
 
-	if err := validateType(zero); err != nil {
+	if err := validateType(zero, o); err != nil {
 		panic(err)
 	}
 
-	result := newRecordMap[T]()
+	result := newRecordMap[T](o)
 
-	cols, err := rows.Columns()
-	if err != nil {
-		return nil, err
+	for next() {
+		fields, err := scanEntry[T](cols, scan, o)
+		if err != nil {
+			return nil, err
+		}
+
+		err = result.merge(fields)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	for rows.Next() {
-		entry := new(T)
+	t := reflect.TypeOf(zero)
 
-		fields, err := newFields(entry)
+	if usesSorting(t, o) {
+		err := result.sort(o)
 		if err != nil {
 			return nil, err
 		}
+	}
 
-		err = fields.scan(cols, rows.Scan)
+	if usesFinalize(t, o) {
+		err := result.finalize(o)
 		if err != nil {
 			return nil, err
 		}
-
-		result.merge(fields)
 	}
 
 	return result.entries, nil
 }
 
+// scanEntry scans the current row (using the provided scan function) into a new T (the provided
+// type), returning the fields of the T ready to be merged.
+func scanEntry[T any](cols []string, scan func(...interface{}) error, o *options) (*fields, error) {
+	entry := new(T)
+
+	fields, err := newFields(entry, o)
+	if err != nil {
+		return nil, err
+	}
+
+	err = fields.scan(cols, scan)
+	if err != nil {
+		return nil, err
+	}
+
+	err = fields.afterScan()
+	if err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
+
 // RowsToStructs will take the data in rows (*sql.Rows) as input and return a slice of
 // Ts (the provided type) as the result.
-func RowsToStructs[T any](rows *sql.Rows) ([]T, error) {
-	return scanRows[T](rows)
+//
+// Options can be provided to alter the way in which the rows are mapped to the Ts.
+func RowsToStructs[T any](rows *sql.Rows, opts ...Option) ([]T, error) {
+	return scanRows[T](rows, newOptions(opts))
 }
 
 // RowsToStruct will take the data in rows (*sql.Rows) as input (similarly to RowsToStructs)
@@ -77,10 +154,10 @@ func RowsToStructs[T any](rows *sql.Rows) ([]T, error) {
 // ErrNoStruct will be returned if zero structs were producible from the provided rows.
 //
 // If more than one struct is produced, an error will be returned.
-func RowsToStruct[T any](rows *sql.Rows) (T, error) {
+func RowsToStruct[T any](rows *sql.Rows, opts ...Option) (T, error) {
 	var zero T // effectively nil (as type is unknown, we can't just return nil)
 
-	result, err := scanRows[T](rows)
+	result, err := scanRows[T](rows, newOptions(opts))
 	if err != nil {
 		return zero, err
 	}
@@ -0,0 +1,222 @@
+// Package goscanqltest provides helpers for testing code that uses goscanql, by building mock rows
+// from entities (rather than hand-writing them) and comparing the entities that rows are scanned
+// into.
+package goscanqltest
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/rustedturnip/goscanql"
+)
+
+// presenceType is the type of goscanql.Presence.
+var presenceType = reflect.TypeOf(goscanql.Presence{})
+
+// MockRows builds a set of sqlmock rows from the provided entities, using the same columns (and
+// the same flattened rows) that goscanql would read them from (see goscanql.StructsToRows). This
+// panics if the entities can't be flattened (e.g. if T isn't a valid goscanql type).
+func MockRows[T any](entities ...T) *sqlmock.Rows {
+	return MockRowsWithOptions(entities)
+}
+
+// MockRowsWithOptions is the same as MockRows, but flattens the entities using the provided
+// options (e.g. a NameMapper).
+func MockRowsWithOptions[T any](entities []T, opts ...goscanql.Option) *sqlmock.Rows {
+	columns, rows, err := goscanql.StructsToRows(entities, opts...)
+	if err != nil {
+		panic(fmt.Sprintf("goscanqltest: unable to build mock rows: %s", err))
+	}
+
+	mockRows := sqlmock.NewRows(columns)
+
+	for _, row := range rows {
+		values := make([]driver.Value, len(row))
+		for i, value := range row {
+			values[i] = value
+		}
+
+		mockRows.AddRow(values...)
+	}
+
+	return mockRows
+}
+
+// AssertScansTo scans the provided rows into entities of type T (using goscanql.RowsToStructs
+// with the provided options), and asserts that they are equal to want. Where they aren't, the
+// test is failed with a line for each difference, naming the path of the difference within
+// the entities, e.g.:
+//
+//	[0].Pets[1].Colour.Red: want 255, got 128
+//
+// Nil and empty slices (and maps) are treated as equal. True is returned if the assertion passed.
+func AssertScansTo[T any](t testing.TB, rows *sql.Rows, want []T, opts ...goscanql.Option) bool {
+	t.Helper()
+
+	got, err := goscanql.RowsToStructs[T](rows, opts...)
+	if err != nil {
+		t.Errorf("goscanqltest: unable to scan rows: %s", err)
+		return false
+	}
+
+	differences := diff(reflect.ValueOf(want), reflect.ValueOf(got))
+	if len(differences) > 0 {
+		t.Errorf("goscanqltest: scanned entities do not match:\n\t%s", strings.Join(differences, "\n\t"))
+		return false
+	}
+
+	return true
+}
+
+// diff returns a line for each difference between the provided values (want and got).
+func diff(want, got reflect.Value) []string {
+	differences := make([]string, 0)
+	diffValues("", want, got, &differences)
+
+	return differences
+}
+
+// diffValues recursively compares the provided values (want and got), adding a line for each
+// difference to differences, prefixed with the provided path.
+func diffValues(path string, want, got reflect.Value, differences *[]string) {
+	if !want.IsValid() || !got.IsValid() {
+		if want.IsValid() != got.IsValid() {
+			addDifference(path, want, got, differences)
+		}
+
+		return
+	}
+
+	if want.Type() != got.Type() {
+		addDifference(path, want, got, differences)
+		return
+	}
+
+	switch want.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if want.IsNil() || got.IsNil() {
+			if want.IsNil() != got.IsNil() {
+				addDifference(path, want, got, differences)
+			}
+
+			return
+		}
+
+		diffValues(path, want.Elem(), got.Elem(), differences)
+
+	case reflect.Struct:
+		// a Presence is filled in by goscanql (rather than scanned), so isn't compared
+		if want.Type() == presenceType {
+			return
+		}
+
+		if !allFieldsExported(want.Type()) {
+			if !reflect.DeepEqual(want.Interface(), got.Interface()) {
+				addDifference(path, want, got, differences)
+			}
+
+			return
+		}
+
+		for i := 0; i < want.NumField(); i++ {
+			name := want.Type().Field(i).Name
+			diffValues(path+"."+name, want.Field(i), got.Field(i), differences)
+		}
+
+	case reflect.Slice, reflect.Array:
+		if want.Kind() == reflect.Slice && want.Type().Elem().Kind() == reflect.Uint8 {
+			if !reflect.DeepEqual(want.Bytes(), got.Bytes()) && (want.Len() > 0 || got.Len() > 0) {
+				addDifference(path, want, got, differences)
+			}
+
+			return
+		}
+
+		if want.Len() != got.Len() {
+			*differences = append(*differences, fmt.Sprintf("%s: want length %d, got length %d", trimPath(path), want.Len(), got.Len()))
+		}
+
+		for i := 0; i < want.Len() || i < got.Len(); i++ {
+			elementPath := fmt.Sprintf("%s[%d]", path, i)
+
+			switch {
+			case i >= got.Len():
+				*differences = append(*differences, fmt.Sprintf("%s: missing %s", trimPath(elementPath), format(want.Index(i))))
+			case i >= want.Len():
+				*differences = append(*differences, fmt.Sprintf("%s: unexpected %s", trimPath(elementPath), format(got.Index(i))))
+			default:
+				diffValues(elementPath, want.Index(i), got.Index(i), differences)
+			}
+		}
+
+	case reflect.Map:
+		for _, key := range want.MapKeys() {
+			keyPath := fmt.Sprintf("%s[%v]", path, key.Interface())
+
+			if !got.MapIndex(key).IsValid() {
+				*differences = append(*differences, fmt.Sprintf("%s: missing %s", trimPath(keyPath), format(want.MapIndex(key))))
+				continue
+			}
+
+			diffValues(keyPath, want.MapIndex(key), got.MapIndex(key), differences)
+		}
+
+		for _, key := range got.MapKeys() {
+			if !want.MapIndex(key).IsValid() {
+				keyPath := fmt.Sprintf("%s[%v]", path, key.Interface())
+				*differences = append(*differences, fmt.Sprintf("%s: unexpected %s", trimPath(keyPath), format(got.MapIndex(key))))
+			}
+		}
+
+	default:
+		if !reflect.DeepEqual(want.Interface(), got.Interface()) {
+			addDifference(path, want, got, differences)
+		}
+	}
+}
+
+// addDifference adds a line to differences describing the difference between the provided
+// values (want and got) at the provided path.
+func addDifference(path string, want, got reflect.Value, differences *[]string) {
+	*differences = append(*differences, fmt.Sprintf("%s: want %s, got %s", trimPath(path), format(want), format(got)))
+}
+
+// format returns a readable representation of the provided value (v).
+func format(v reflect.Value) string {
+	if !v.IsValid() {
+		return "<nil>"
+	}
+
+	if (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && v.IsNil() {
+		return "nil"
+	}
+
+	return fmt.Sprintf("%#v", v.Interface())
+}
+
+// trimPath removes the leading separator of the provided path (so that the fields of a root
+// struct are named without a preceding dot), or returns "value" where the path is empty.
+func trimPath(path string) string {
+	path = strings.TrimPrefix(path, ".")
+	if path == "" {
+		return "value"
+	}
+
+	return path
+}
+
+// allFieldsExported returns true if every field of the provided struct type (t) is exported.
+func allFieldsExported(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if !t.Field(i).IsExported() {
+			return false
+		}
+	}
+
+	return true
+}
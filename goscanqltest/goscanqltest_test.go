@@ -0,0 +1,153 @@
+package goscanqltest
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/rustedturnip/goscanql"
+	"github.com/stretchr/testify/assert"
+)
+
+type testColour struct {
+	Red   int `sql:"red"`
+	Green int `sql:"green"`
+}
+
+type testPet struct {
+	Name   string      `sql:"name"`
+	Colour *testColour `sql:"colour"`
+}
+
+type testAccount struct {
+	ID          int                 `sql:"id"`
+	Nickname    goscanql.NullString `sql:"nickname"`
+	DateOfBirth time.Time           `sql:"date_of_birth"`
+	Pets        []testPet           `sql:"pets"`
+	Aliases     []string            `sql:"alias"`
+}
+
+var testAccounts = []*testAccount{
+	{
+		ID:          1,
+		Nickname:    goscanql.NullString{String: "Duchess", Valid: true},
+		DateOfBirth: time.Date(1978, 12, 30, 0, 0, 0, 0, time.UTC),
+		Pets: []testPet{
+			{Name: "Babou", Colour: &testColour{Red: 255, Green: 128}},
+			{Name: "Gustavo"},
+		},
+		Aliases: []string{"Randy", "Archer"},
+	},
+	{
+		ID:          2,
+		DateOfBirth: time.Date(1987, 4, 24, 0, 0, 0, 0, time.UTC),
+	},
+}
+
+func TestMockRows(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		panic(err)
+	}
+
+	mock.ExpectQuery("SELECT").WillReturnRows(MockRows(testAccounts...))
+
+	rows, err := db.Query("SELECT")
+	if err != nil {
+		panic(err)
+	}
+
+	// Act & Assert
+	AssertScansTo(t, rows, testAccounts)
+}
+
+func TestMockRowsWithPresence(t *testing.T) {
+	type profile struct {
+		goscanql.Presence
+
+		ID  int    `sql:"id"`
+		Bio string `sql:"bio"`
+	}
+
+	profiles := []*profile{{ID: 1, Bio: "Spy"}, {ID: 2}}
+
+	// Arrange
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		panic(err)
+	}
+
+	mock.ExpectQuery("SELECT").WillReturnRows(MockRows(profiles...))
+
+	rows, err := db.Query("SELECT")
+	if err != nil {
+		panic(err)
+	}
+
+	// Act & Assert
+	AssertScansTo(t, rows, profiles)
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		want     []*testAccount
+		got      []*testAccount
+		expected []string
+	}{
+		{
+			name:     "GivenEqualEntities_ThenNoDifferences",
+			want:     testAccounts,
+			got:      testAccounts,
+			expected: []string{},
+		},
+		{
+			name:     "GivenNilAndEmptySlices_ThenNoDifferences",
+			want:     []*testAccount{{ID: 1, Pets: []testPet{}}},
+			got:      []*testAccount{{ID: 1}},
+			expected: []string{},
+		},
+		{
+			name: "GivenNestedDifferences_ThenPathsReported",
+			want: []*testAccount{
+				{ID: 1, Pets: []testPet{{Name: "Babou", Colour: &testColour{Red: 255}}}},
+			},
+			got: []*testAccount{
+				{ID: 1, Pets: []testPet{{Name: "Babou", Colour: &testColour{Red: 128}}, {Name: "Gustavo"}}},
+				{ID: 2},
+			},
+			expected: []string{
+				"value: want length 1, got length 2",
+				"[0].Pets: want length 1, got length 2",
+				"[0].Pets[0].Colour.Red: want 255, got 128",
+				`[0].Pets[1]: unexpected goscanqltest.testPet{Name:"Gustavo", Colour:(*goscanqltest.testColour)(nil)}`,
+				"[1]: unexpected &goscanqltest.testAccount{ID:2, Nickname:goscanql.NullString{String:\"\", Valid:false}, DateOfBirth:time.Date(1, time.January, 1, 0, 0, 0, 0, time.UTC), Pets:[]goscanqltest.testPet(nil), Aliases:[]string(nil)}",
+			},
+		},
+		{
+			name: "GivenNilPointerAndUnexportedStruct_ThenValuesReported",
+			want: []*testAccount{
+				{ID: 1, DateOfBirth: time.Date(1978, 12, 30, 0, 0, 0, 0, time.UTC), Pets: []testPet{{Name: "Babou"}}},
+			},
+			got: []*testAccount{
+				{ID: 1, Pets: []testPet{{Name: "Babou", Colour: &testColour{}}}},
+			},
+			expected: []string{
+				"[0].DateOfBirth: want time.Date(1978, time.December, 30, 0, 0, 0, 0, time.UTC), got time.Date(1, time.January, 1, 0, 0, 0, 0, time.UTC)",
+				"[0].Pets[0].Colour: want nil, got &goscanqltest.testColour{Red:0, Green:0}",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Act
+			differences := diff(reflect.ValueOf(test.want), reflect.ValueOf(test.got))
+
+			// Assert
+			assert.Equal(t, test.expected, differences)
+		})
+	}
+}
@@ -0,0 +1,468 @@
+package goscanql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultBatchSize is the maximum number of rows that are inserted by a single statement of
+	// SaveGraph, unless otherwise provided with WithBatchSize.
+	defaultBatchSize = 100
+)
+
+// GraphMapping describes how a level of entities (and their one-to-many children) are saved by
+// SaveGraph.
+type GraphMapping struct {
+
+	// Table is the table that the entities are inserted into.
+	Table string
+
+	// Key is the column that holds the key of an entity (e.g. "id"). Where the key of an entity is
+	// its zero value, the key is omitted from the insert so that it is generated by the database,
+	// and the generated key is then written back to the entity. The key must be provided for any
+	// entity with children to be saved.
+	Key string
+
+	// ForeignKey is the column of the table that holds the key of an entity's parent, which is
+	// populated from the parent (ignored for root entities).
+	ForeignKey string
+
+	// Children maps the name of each one-to-many relationship of the entity to the mapping that it
+	// should be saved with. One-to-many relationships without a mapping are not saved.
+	Children map[string]GraphMapping
+}
+
+// Executor represents anything that statements can be executed against, such as *sql.DB, *sql.Tx
+// or *sql.Conn.
+type Executor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// returningDialect is implemented by Dialects that support returning the generated keys of an
+// insert with a RETURNING clause.
+type returningDialect interface {
+	supportsReturning() bool
+}
+
+func (postgresDialect) supportsReturning() bool {
+	return true
+}
+
+func (sqliteDialect) supportsReturning() bool {
+	return true
+}
+
+// supportsReturning returns true if the provided dialect supports a RETURNING clause.
+func supportsReturning(d Dialect) bool {
+	r, ok := d.(returningDialect)
+	return ok && r.supportsReturning()
+}
+
+// consecutiveIdDialect is implemented by Dialects where LastInsertId returns the generated key of
+// the first row of a multi-row insert, and the keys of the remaining rows follow consecutively.
+type consecutiveIdDialect interface {
+	hasConsecutiveIds() bool
+}
+
+func (mysqlDialect) hasConsecutiveIds() bool {
+	return true
+}
+
+// hasConsecutiveIds returns true if the generated keys of a multi-row insert in the provided
+// dialect are consecutive, starting from LastInsertId.
+func hasConsecutiveIds(d Dialect) bool {
+	c, ok := d.(consecutiveIdDialect)
+	return ok && c.hasConsecutiveIds()
+}
+
+// graphEntity represents a single entity being saved by SaveGraph.
+type graphEntity struct {
+
+	// value is the (addressable) struct of the entity.
+	value reflect.Value
+
+	// parentKey is the key of the entity's parent (nil for root entities).
+	parentKey interface{}
+}
+
+// SaveGraph inserts the provided root entities, and their one-to-many children, into the tables
+// described by the provided mapping, using batched multi-row inserts for each level.
+//
+// The columns inserted for each entity are the same as InsertStatement (so one-to-one
+// relationships are flattened), and the key of each parent is written into the foreign key
+// column of its children. Where the key of an entity is generated by the database, it is
+// retrieved with a RETURNING clause (Postgres and SQLite) or from the last insert id (MySQL, whose
+// keys of a single multi-row insert are consecutive), so a Dialect must be provided with
+// WithDialect. As the rows returned by RETURNING aren't guaranteed to be in the order that they
+// were inserted, the inserted columns are returned along with the key, and each returned row is
+// matched to the entity with the same values (entities with identical values are interchangeable).
+//
+// SaveGraph does not manage transactions, so should be provided with a *sql.Tx if the graph is to
+// be saved atomically.
+func SaveGraph[T any](ctx context.Context, tx Executor, roots []T, mapping GraphMapping, opts ...Option) error {
+	var zero T
+
+	o := newOptions(opts)
+
+	err := validateType(zero, o)
+	if err != nil {
+		return err
+	}
+
+	rv := reflect.ValueOf(roots)
+	entities := make([]graphEntity, 0, rv.Len())
+
+	for i := 0; i < rv.Len(); i++ {
+		v := indirectValue(rv.Index(i))
+		if !v.IsValid() {
+			continue
+		}
+
+		entities = append(entities, graphEntity{value: v})
+	}
+
+	return saveLevel(ctx, tx, getPointerRootType(reflect.TypeOf(zero)), entities, mapping, o)
+}
+
+// saveLevel inserts the provided entities (of type t) with the provided mapping, and then
+// recursively saves their children.
+func saveLevel(ctx context.Context, tx Executor, t reflect.Type, entities []graphEntity, mapping GraphMapping, o *options) error {
+	if len(entities) == 0 {
+		return nil
+	}
+
+	if mapping.Key == "" && len(mapping.Children) > 0 {
+		return fmt.Errorf("goscanql: a key must be provided to save the children of table %s", mapping.Table)
+	}
+
+	generated := make([]graphEntity, 0)
+	provided := make([]graphEntity, 0)
+
+	for _, entity := range entities {
+		key, err := graphKey(entity.value, mapping, o)
+		if err != nil {
+			return err
+		}
+
+		if key.IsValid() && key.IsZero() {
+			generated = append(generated, entity)
+			continue
+		}
+
+		provided = append(provided, entity)
+	}
+
+	if len(generated) > 0 && !supportsReturning(o.getDialect()) && !hasConsecutiveIds(o.getDialect()) {
+		return fmt.Errorf("goscanql: a dialect that supports generated keys must be provided to generate the keys of table %s", mapping.Table)
+	}
+
+	for start := 0; start < len(generated); start += o.getBatchSize() {
+		end := minInt(start+o.getBatchSize(), len(generated))
+
+		err := insertBatch(ctx, tx, t, generated[start:end], mapping, true, o)
+		if err != nil {
+			return err
+		}
+	}
+
+	for start := 0; start < len(provided); start += o.getBatchSize() {
+		end := minInt(start+o.getBatchSize(), len(provided))
+
+		err := insertBatch(ctx, tx, t, provided[start:end], mapping, false, o)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, field := range structFields(t, o) {
+		childMapping, ok := mapping.Children[field.tag.name]
+		if !ok || field.kind != oneToManyKind {
+			continue
+		}
+
+		children := make([]graphEntity, 0)
+
+		for _, entity := range entities {
+			key, err := graphKey(entity.value, mapping, o)
+			if err != nil {
+				return err
+			}
+
+			slice := indirectValue(entity.value.FieldByIndex(field.field.Index))
+			if !slice.IsValid() {
+				continue
+			}
+
+			for i := 0; i < slice.Len(); i++ {
+				child := indirectValue(slice.Index(i))
+				if !child.IsValid() {
+					continue
+				}
+
+				children = append(children, graphEntity{value: child, parentKey: key.Interface()})
+			}
+		}
+
+		err := saveLevel(ctx, tx, field.elemType(), children, childMapping, o)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// graphKey returns the key field of the provided entity (v), or an invalid reflect.Value if the
+// mapping has no key.
+func graphKey(v reflect.Value, mapping GraphMapping, o *options) (reflect.Value, error) {
+	if mapping.Key == "" {
+		return reflect.Value{}, nil
+	}
+
+	key := fieldByTag(mapping.Key, v, o)
+	if key == nil {
+		return reflect.Value{}, fmt.Errorf("goscanql: key %s is not a field of %s", mapping.Key, v.Type().String())
+	}
+
+	return *key, nil
+}
+
+// insertBatch inserts the provided entities with a single multi-row insert. If generateKeys is
+// true, the key column is omitted from the insert and the generated keys are written back to the
+// entities.
+func insertBatch(ctx context.Context, tx Executor, t reflect.Type, entities []graphEntity, mapping GraphMapping, generateKeys bool, o *options) error {
+	dialect := o.getDialect()
+
+	columns := make([]string, 0)
+	rows := make([]string, len(entities))
+	args := make([]interface{}, 0)
+
+	// inserted holds the values inserted for each entity, which its returned row is matched by
+	inserted := make([][]interface{}, len(entities))
+
+	for i, entity := range entities {
+		values, err := collectValues(t, entity.value, "", o)
+		if err != nil {
+			return err
+		}
+
+		if mapping.ForeignKey != "" && entity.parentKey != nil {
+			values = setColumnValue(values, mapping.ForeignKey, entity.parentKey)
+		}
+
+		placeholders := make([]string, 0, len(values))
+
+		for _, value := range values {
+			if generateKeys && value.name == mapping.Key {
+				continue
+			}
+
+			if i == 0 {
+				columns = append(columns, dialect.Quote(value.name))
+			}
+
+			args = append(args, value.value)
+			placeholders = append(placeholders, dialect.Placeholder(len(args)))
+			inserted[i] = append(inserted[i], value.value)
+		}
+
+		rows[i] = fmt.Sprintf("(%s)", strings.Join(placeholders, ", "))
+	}
+
+	statement := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s",
+		dialect.Quote(mapping.Table), strings.Join(columns, ", "), strings.Join(rows, ", "))
+
+	if !generateKeys {
+		_, err := tx.ExecContext(ctx, statement, args...)
+		return err
+	}
+
+	if supportsReturning(dialect) {
+		returning := append([]string{dialect.Quote(mapping.Key)}, columns...)
+		statement = fmt.Sprintf("%s RETURNING %s", statement, strings.Join(returning, ", "))
+
+		return insertReturning(ctx, tx, statement, args, entities, inserted, mapping, o)
+	}
+
+	result, err := tx.ExecContext(ctx, statement, args...)
+	if err != nil {
+		return err
+	}
+
+	first, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	for i, entity := range entities {
+		err := setKey(entity.value, first+int64(i), mapping, o)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// insertReturning executes the provided multi-row insert statement (that returns the generated
+// key followed by the inserted columns), and writes each returned key back to the entity whose
+// inserted values match the rest of the returned row.
+func insertReturning(ctx context.Context, tx Executor, statement string, args []interface{}, entities []graphEntity, inserted [][]interface{}, mapping GraphMapping, o *options) error {
+	rows, err := tx.QueryContext(ctx, statement, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	matched := make([]bool, len(entities))
+
+	for rows.Next() {
+		key, err := graphKey(entities[0].value, mapping, o)
+		if err != nil {
+			return err
+		}
+
+		returnedKey := reflect.New(key.Type())
+		returned := make([]interface{}, len(inserted[0]))
+
+		dest := []interface{}{returnedKey.Interface()}
+		for i := range returned {
+			dest = append(dest, &returned[i])
+		}
+
+		err = rows.Scan(dest...)
+		if err != nil {
+			return err
+		}
+
+		i := matchReturned(returned, inserted, matched)
+		if i < 0 {
+			return fmt.Errorf("goscanql: unable to match a key returned by %s to an inserted row", mapping.Table)
+		}
+
+		matched[i] = true
+
+		key, err = graphKey(entities[i].value, mapping, o)
+		if err != nil {
+			return err
+		}
+
+		key.Set(returnedKey.Elem())
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, ok := range matched {
+		if !ok {
+			return fmt.Errorf("goscanql: fewer keys returned than rows inserted into %s", mapping.Table)
+		}
+	}
+
+	return nil
+}
+
+// matchReturned returns the index of the first unmatched row of inserted values whose values are
+// the same as the provided returned values, or -1 if there is none.
+func matchReturned(returned []interface{}, inserted [][]interface{}, matched []bool) int {
+	for i, values := range inserted {
+		if matched[i] || len(values) != len(returned) {
+			continue
+		}
+
+		same := true
+
+		for j := range values {
+			same = same && correlationValue(values[j]) == correlationValue(returned[j])
+		}
+
+		if same {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// correlationValue returns a representation of the provided value (either inserted, or returned
+// by the database) that can be compared regardless of the type that the driver represents it by.
+func correlationValue(value interface{}) string {
+	value, err := driver.DefaultParameterConverter.ConvertValue(value)
+	if err != nil {
+		return fmt.Sprintf("%#v", value)
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	case bool:
+		if v {
+			return "1"
+		}
+
+		return "0"
+	}
+
+	return fmt.Sprint(value)
+}
+
+// setKey writes the provided generated key (an id from LastInsertId) to the key field of the
+// provided entity (v), allocating the key where it is a nil pointer.
+func setKey(v reflect.Value, id int64, mapping GraphMapping, o *options) error {
+	key, err := graphKey(v, mapping, o)
+	if err != nil {
+		return err
+	}
+
+	target := key.Type()
+	if target.Kind() == reflect.Pointer {
+		target = target.Elem()
+	}
+
+	idValue := reflect.ValueOf(id)
+	if !idValue.CanConvert(target) {
+		return fmt.Errorf("goscanql: unable to write generated key to field %s of type %s", mapping.Key, key.Type().String())
+	}
+
+	if key.Kind() == reflect.Pointer {
+		key.Set(reflect.New(target))
+		key = key.Elem()
+	}
+
+	key.Set(idValue.Convert(target))
+	return nil
+}
+
+// setColumnValue sets the value of the named column in values, adding the column if it isn't
+// already present.
+func setColumnValue(values []columnValue, name string, value interface{}) []columnValue {
+	for i := range values {
+		if values[i].name == name {
+			values[i].value = value
+			return values
+		}
+	}
+
+	return append(values, columnValue{name: name, value: value})
+}
+
+// minInt returns the smaller of a and b.
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,287 @@
+package goscanql
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+type graphTestToy struct {
+	Name string `sql:"name"`
+}
+
+type graphTestPet struct {
+	ID   int64          `sql:"id"`
+	Name string         `sql:"name"`
+	Toys []graphTestToy `sql:"toys"`
+}
+
+type graphTestAccount struct {
+	ID      int64          `sql:"id"`
+	Email   string         `sql:"email"`
+	Pets    []graphTestPet `sql:"pets"`
+	Aliases []string       `sql:"alias"`
+}
+
+var graphTestMapping = GraphMapping{
+	Table: "account",
+	Key:   "id",
+	Children: map[string]GraphMapping{
+		"pets": {
+			Table:      "pet",
+			Key:        "id",
+			ForeignKey: "account_id",
+			Children: map[string]GraphMapping{
+				"toys": {
+					Table:      "toy",
+					ForeignKey: "pet_id",
+				},
+			},
+		},
+	},
+}
+
+func newGraphTestAccounts() []*graphTestAccount {
+	return []*graphTestAccount{
+		{
+			Email: "sterling.archer@isis.com",
+			Pets: []graphTestPet{
+				{Name: "Babou", Toys: []graphTestToy{{Name: "ball"}, {Name: "rope"}}},
+			},
+			Aliases: []string{"Duchess"},
+		},
+		{
+			ID:    7,
+			Email: "cheryl.tunt@isis.com",
+			Pets: []graphTestPet{
+				{Name: "Gustavo"},
+			},
+		},
+		{
+			Email: "pam.poovey@isis.com",
+		},
+	}
+}
+
+func TestSaveGraph_Returning(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		panic(err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "account" ("email") VALUES ($1), ($2) RETURNING "id", "email"`)).
+		WithArgs("sterling.archer@isis.com", "pam.poovey@isis.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "email"}).
+			AddRow(2, "pam.poovey@isis.com").
+			AddRow(1, "sterling.archer@isis.com"))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "account" ("id", "email") VALUES ($1, $2)`)).
+		WithArgs(7, "cheryl.tunt@isis.com").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "pet" ("name", "account_id") VALUES ($1, $2), ($3, $4) RETURNING "id", "name", "account_id"`)).
+		WithArgs("Babou", 1, "Gustavo", 7).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "account_id"}).
+			AddRow(11, []byte("Gustavo"), int64(7)).
+			AddRow(10, []byte("Babou"), int64(1)))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "toy" ("name", "pet_id") VALUES ($1, $2), ($3, $4)`)).
+		WithArgs("ball", 10, "rope", 10).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	tx, err := db.Begin()
+	if err != nil {
+		panic(err)
+	}
+
+	accounts := newGraphTestAccounts()
+
+	// Act
+	err = SaveGraph(context.Background(), tx, accounts, graphTestMapping, WithDialect(Postgres))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Nil(t, tx.Commit())
+	assert.Nil(t, mock.ExpectationsWereMet())
+
+	assert.Equal(t, int64(1), accounts[0].ID)
+	assert.Equal(t, int64(7), accounts[1].ID)
+	assert.Equal(t, int64(2), accounts[2].ID)
+	assert.Equal(t, int64(10), accounts[0].Pets[0].ID)
+	assert.Equal(t, int64(11), accounts[1].Pets[0].ID)
+}
+
+func TestSaveGraph_ReturningErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		returned    *sqlmock.Rows
+		expectedErr error
+	}{
+		{
+			name:        "GivenNoKeyReturned_ThenErrorReturned",
+			returned:    sqlmock.NewRows([]string{"id", "email"}),
+			expectedErr: fmt.Errorf("goscanql: fewer keys returned than rows inserted into account"),
+		},
+		{
+			name:        "GivenUnmatchedRowReturned_ThenErrorReturned",
+			returned:    sqlmock.NewRows([]string{"id", "email"}).AddRow(1, "ray.gillette@isis.com"),
+			expectedErr: fmt.Errorf("goscanql: unable to match a key returned by account to an inserted row"),
+		},
+		{
+			name: "GivenMoreKeysReturned_ThenErrorReturned",
+			returned: sqlmock.NewRows([]string{"id", "email"}).
+				AddRow(1, "lana.kane@isis.com").
+				AddRow(2, "lana.kane@isis.com"),
+			expectedErr: fmt.Errorf("goscanql: unable to match a key returned by account to an inserted row"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Arrange
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				panic(err)
+			}
+
+			mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "account" ("email") VALUES ($1) RETURNING "id", "email"`)).
+				WillReturnRows(test.returned)
+
+			accounts := []graphTestAccount{{Email: "lana.kane@isis.com"}}
+
+			// Act
+			err = SaveGraph(context.Background(), db, accounts, GraphMapping{Table: "account", Key: "id"}, WithDialect(Postgres))
+
+			// Assert
+			assert.Equal(t, test.expectedErr, err)
+		})
+	}
+}
+
+func TestSaveGraph_LastInsertId(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		panic(err)
+	}
+
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `account` (`email`) VALUES (?)")).
+		WithArgs("sterling.archer@isis.com").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `account` (`email`) VALUES (?)")).
+		WithArgs("pam.poovey@isis.com").
+		WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `account` (`id`, `email`) VALUES (?, ?)")).
+		WithArgs(7, "cheryl.tunt@isis.com").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `pet` (`name`, `account_id`) VALUES (?, ?)")).
+		WithArgs("Babou", 1).
+		WillReturnResult(sqlmock.NewResult(20, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `pet` (`name`, `account_id`) VALUES (?, ?)")).
+		WithArgs("Gustavo", 7).
+		WillReturnResult(sqlmock.NewResult(21, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `toy` (`name`, `pet_id`) VALUES (?, ?)")).
+		WithArgs("ball", 20).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `toy` (`name`, `pet_id`) VALUES (?, ?)")).
+		WithArgs("rope", 20).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	accounts := newGraphTestAccounts()
+
+	// Act
+	err = SaveGraph(context.Background(), db, accounts, graphTestMapping, WithDialect(MySQL), WithBatchSize(1))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Nil(t, mock.ExpectationsWereMet())
+
+	assert.Equal(t, int64(1), accounts[0].ID)
+	assert.Equal(t, int64(2), accounts[2].ID)
+	assert.Equal(t, int64(20), accounts[0].Pets[0].ID)
+	assert.Equal(t, int64(21), accounts[1].Pets[0].ID)
+}
+
+func TestSaveGraph_PointerKey(t *testing.T) {
+	type account struct {
+		ID    *int64 `sql:"id"`
+		Email string `sql:"email"`
+	}
+
+	// Arrange
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		panic(err)
+	}
+
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `account` (`email`) VALUES (?), (?)")).
+		WithArgs("sterling.archer@isis.com", "pam.poovey@isis.com").
+		WillReturnResult(sqlmock.NewResult(4, 2))
+
+	accounts := []account{{Email: "sterling.archer@isis.com"}, {Email: "pam.poovey@isis.com"}}
+
+	// Act
+	err = SaveGraph(context.Background(), db, accounts, GraphMapping{Table: "account", Key: "id"}, WithDialect(MySQL))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Nil(t, mock.ExpectationsWereMet())
+
+	if assert.NotNil(t, accounts[0].ID) && assert.NotNil(t, accounts[1].ID) {
+		assert.Equal(t, int64(4), *accounts[0].ID)
+		assert.Equal(t, int64(5), *accounts[1].ID)
+	}
+}
+
+func TestSaveGraph_Errors(t *testing.T) {
+	tests := []struct {
+		name        string
+		mapping     GraphMapping
+		expectedErr error
+	}{
+		{
+			name: "GivenChildrenWithoutKey_ThenErrorReturned",
+			mapping: GraphMapping{
+				Table:    "account",
+				Children: map[string]GraphMapping{"pets": {Table: "pet"}},
+			},
+			expectedErr: fmt.Errorf("goscanql: a key must be provided to save the children of table account"),
+		},
+		{
+			name: "GivenUnknownKey_ThenErrorReturned",
+			mapping: GraphMapping{
+				Table: "account",
+				Key:   "account_id",
+			},
+			expectedErr: fmt.Errorf("goscanql: key account_id is not a field of goscanql.graphTestAccount"),
+		},
+		{
+			name: "GivenGeneratedKeysWithoutDialect_ThenErrorReturned",
+			mapping: GraphMapping{
+				Table: "account",
+				Key:   "id",
+			},
+			expectedErr: fmt.Errorf("goscanql: a dialect that supports generated keys must be provided to generate the keys of table account"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Arrange
+			db, _, err := sqlmock.New()
+			if err != nil {
+				panic(err)
+			}
+
+			// Act
+			err = SaveGraph(context.Background(), db, newGraphTestAccounts(), test.mapping)
+
+			// Assert
+			assert.Equal(t, test.expectedErr, err)
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package goscanql
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// AfterScanner can be implemented by an entity to have goscanql call AfterScan each time a row
+// has been scanned into it (before the row is merged into the result). Any error returned will
+// abort the scan.
+type AfterScanner interface {
+	AfterScan() error
+}
+
+// AfterMerger can be implemented by an entity to have goscanql call AfterMerge each time a row
+// has been merged into it (where the row matched the existing entity, rather than producing a new
+// one). Any error returned will abort the scan.
+type AfterMerger interface {
+	AfterMerge() error
+}
+
+// Finalizer can be implemented by an entity to have goscanql call Finalize once for the entity
+// when the scan has completed, e.g. to compute any derived fields. Children are finalized before
+// their parents. Any error returned will abort the scan.
+type Finalizer interface {
+	Finalize() error
+}
+
+// Validator can be implemented by an entity to have goscanql call Validate once for the entity
+// when the scan has completed (after Finalize), e.g. to enforce any invariants. Children are
+// validated before their parents. Any error returned will abort the scan.
+type Validator interface {
+	Validate() error
+}
+
+var (
+	// afterScannerType is the type of the AfterScanner interface.
+	afterScannerType = reflect.TypeOf((*AfterScanner)(nil)).Elem()
+
+	// finalizerType is the type of the Finalizer interface.
+	finalizerType = reflect.TypeOf((*Finalizer)(nil)).Elem()
+
+	// validatorType is the type of the Validator interface.
+	validatorType = reflect.TypeOf((*Validator)(nil)).Elem()
+)
+
+// newHookError wraps an error returned by the named hook of an entity (v) with the context of
+// where it came from.
+func newHookError(hook string, v reflect.Value, err error) error {
+	return fmt.Errorf("goscanql: %s failed for %s: %w", hook, v.Type().String(), err)
+}
+
+// callAfterScan will call AfterScan on the provided entity (v) if it implements AfterScanner.
+func callAfterScan(v reflect.Value) error {
+	hook, ok := asHook[AfterScanner](v)
+	if !ok {
+		return nil
+	}
+
+	err := hook.AfterScan()
+	if err != nil {
+		return newHookError("AfterScan", v, err)
+	}
+
+	return nil
+}
+
+// callAfterMerge will call AfterMerge on the provided entity (v) if it implements AfterMerger.
+func callAfterMerge(v reflect.Value) error {
+	hook, ok := asHook[AfterMerger](v)
+	if !ok {
+		return nil
+	}
+
+	err := hook.AfterMerge()
+	if err != nil {
+		return newHookError("AfterMerge", v, err)
+	}
+
+	return nil
+}
+
+// callFinalizeAndValidate will call Finalize and then Validate on the provided entity (v) if it
+// implements Finalizer and Validator respectively.
+func callFinalizeAndValidate(v reflect.Value) error {
+	if hook, ok := asHook[Finalizer](v); ok {
+		err := hook.Finalize()
+		if err != nil {
+			return newHookError("Finalize", v, err)
+		}
+	}
+
+	if hook, ok := asHook[Validator](v); ok {
+		err := hook.Validate()
+		if err != nil {
+			return newHookError("Validate", v, err)
+		}
+	}
+
+	return nil
+}
+
+// asHook returns the provided entity (v) as a hook of type H, if either the entity or a pointer
+// to the entity implements H.
+func asHook[H any](v reflect.Value) (H, bool) {
+	if v.CanAddr() {
+		v = v.Addr()
+	}
+
+	hook, ok := v.Interface().(H)
+	return hook, ok
+}
+
+// afterScan will call AfterScan on each non-nil entity held by the fields (including all of its
+// children).
+func (f *fields) afterScan() error {
+	if !f.features.has(afterScanFeature) {
+		return nil
+	}
+
+	var err error
+
+	f.crawlFields(func(_ string, fi *fields) bool {
+		if err != nil || fi.isNil() {
+			return true
+		}
+
+		err = callAfterScan(getRootValue(reflect.ValueOf(fi.obj)))
+		return err != nil
+	})
+
+	return err
+}
+
+// usesFinalize returns true if the provided type (t) or any of its children implement Finalizer or
+// Validator.
+func usesFinalize(t reflect.Type, o *options) bool {
+	found := false
+
+	_ = traverseType(t, func(t reflect.Type) error {
+		pt := reflect.PointerTo(t)
+
+		for _, hook := range []reflect.Type{finalizerType, validatorType} {
+			found = found || t.Implements(hook) || pt.Implements(hook)
+		}
+
+		return nil
+	}, o)
+
+	return found
+}
+
+// finalizeEntities will call Finalize and Validate on the provided value (v) and each of its
+// children, children first. Entities that are shared (see WithIdentityMap) are only visited once.
+func finalizeEntities(v reflect.Value, o *options, visited map[uintptr]bool) error {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+
+		if v.Kind() == reflect.Pointer {
+			if visited[v.Pointer()] {
+				return nil
+			}
+
+			visited[v.Pointer()] = true
+		}
+
+		v = v.Elem()
+	}
+
+	if v.Kind() == reflect.Slice && !implementsScanner(reflect.PointerTo(v.Type())) {
+		for i := 0; i < v.Len(); i++ {
+			err := finalizeEntities(v.Index(i), o, visited)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	if !isOneToOne(v, o) {
+		return nil
+	}
+
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		if !isGoscanqlField(t, i, o) {
+			continue
+		}
+
+		err := finalizeEntities(v.Field(i), o, visited)
+		if err != nil {
+			return err
+		}
+	}
+
+	return callFinalizeAndValidate(v)
+}
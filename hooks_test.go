@@ -0,0 +1,139 @@
+package goscanql
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+// hookTestPet implements every hook, recording the calls made so that they can be asserted on.
+// A hook will return an error if the pet's name is prefixed with "fail" and the name of the hook.
+type hookTestPet struct {
+	Name      string   `sql:"name"`
+	Calls     []string // not mapped by goscanql
+	UpperName string   // derived in Finalize
+}
+
+func (p *hookTestPet) hook(name string) error {
+	p.Calls = append(p.Calls, name)
+
+	if strings.HasPrefix(p.Name, "fail"+name) {
+		return errors.New("invalid pet")
+	}
+
+	return nil
+}
+
+func (p *hookTestPet) AfterScan() error {
+	p.Name = strings.TrimSpace(p.Name)
+	return p.hook("AfterScan")
+}
+
+func (p *hookTestPet) AfterMerge() error {
+	return p.hook("AfterMerge")
+}
+
+func (p *hookTestPet) Finalize() error {
+	p.UpperName = strings.ToUpper(p.Name)
+	return p.hook("Finalize")
+}
+
+func (p *hookTestPet) Validate() error {
+	return p.hook("Validate")
+}
+
+// hookTestOwner implements Finalizer, recording the names of its pets at the time it is called
+// to assert that children are finalized first.
+type hookTestOwner struct {
+	ID       int           `sql:"id"`
+	Pets     []hookTestPet `sql:"pets"`
+	PetNames []string
+}
+
+func (o *hookTestOwner) Finalize() error {
+	for _, pet := range o.Pets {
+		o.PetNames = append(o.PetNames, pet.UpperName)
+	}
+
+	return nil
+}
+
+func TestHooks(t *testing.T) {
+	tests := []struct {
+		name        string
+		petNames    []interface{}
+		expected    []hookTestOwner
+		expectedErr error
+	}{
+		{
+			name:     "GivenHooks_ThenHooksCalledInOrder",
+			petNames: []interface{}{" Babou ", "Babou", "Gustavo"},
+			expected: []hookTestOwner{
+				{
+					ID: 1,
+					Pets: []hookTestPet{
+						{
+							Name:      "Babou",
+							Calls:     []string{"AfterScan", "AfterMerge", "Finalize", "Validate"},
+							UpperName: "BABOU",
+						},
+						{
+							Name:      "Gustavo",
+							Calls:     []string{"AfterScan", "Finalize", "Validate"},
+							UpperName: "GUSTAVO",
+						},
+					},
+					PetNames: []string{"BABOU", "GUSTAVO"},
+				},
+			},
+		},
+		{
+			name:        "GivenAfterScanError_ThenScanAborted",
+			petNames:    []interface{}{"Babou", "failAfterScan"},
+			expectedErr: fmt.Errorf("goscanql: AfterScan failed for goscanql.hookTestPet: %w", errors.New("invalid pet")),
+		},
+		{
+			name:        "GivenAfterMergeError_ThenScanAborted",
+			petNames:    []interface{}{"failAfterMerge", "failAfterMerge"},
+			expectedErr: fmt.Errorf("goscanql: AfterMerge failed for goscanql.hookTestPet: %w", errors.New("invalid pet")),
+		},
+		{
+			name:        "GivenValidateError_ThenScanAborted",
+			petNames:    []interface{}{"Babou", "failValidate"},
+			expectedErr: fmt.Errorf("goscanql: Validate failed for goscanql.hookTestPet: %w", errors.New("invalid pet")),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Arrange
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				panic(err)
+			}
+
+			inputRows := sqlmock.NewRows([]string{"id", "pets_name"})
+			for _, name := range test.petNames {
+				inputRows.AddRow(1, name)
+			}
+
+			mock.ExpectQuery("SELECT").WillReturnRows(inputRows)
+
+			rows, err := db.Query("SELECT")
+			if err != nil {
+				panic(err)
+			}
+
+			// Act
+			result, err := RowsToStructs[hookTestOwner](rows)
+
+			// Assert
+			assert.Equal(t, test.expectedErr, err)
+			assert.Equal(t, test.expected, result)
+		})
+	}
+}
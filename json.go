@@ -0,0 +1,315 @@
+package goscanql
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+const (
+	jsonTag = "json"
+)
+
+// jsonMarshalerType is the type of the json.Marshaler interface.
+var jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+// jsonArrayWriter writes the elements of a JSON array to a writer one at a time.
+type jsonArrayWriter struct {
+	w     io.Writer
+	count int
+}
+
+// write writes the provided (encoded) element to the array, opening the array if it is the
+// first element.
+func (jw *jsonArrayWriter) write(element []byte) error {
+	separator := ","
+	if jw.count == 0 {
+		separator = "["
+	}
+
+	jw.count++
+
+	_, err := jw.w.Write(append([]byte(separator), element...))
+	return err
+}
+
+// close closes the array (opening it first if no elements were written).
+func (jw *jsonArrayWriter) close() error {
+	end := "]"
+	if jw.count == 0 {
+		end = "[]"
+	}
+
+	_, err := io.WriteString(jw.w, end)
+	return err
+}
+
+// writeEntities encodes each of the provided entities and writes them to the array.
+func (jw *jsonArrayWriter) writeEntities(entities reflect.Value, o *options) error {
+	for i := 0; i < entities.Len(); i++ {
+		element, err := appendJSON(nil, entities.Index(i), o)
+		if err != nil {
+			return err
+		}
+
+		err = jw.write(element)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// appendJSON appends the JSON encoding of the provided value (v) to b. Structs are encoded as
+// objects of the fields that goscanql maps (named by their json tag, or otherwise by the name
+// goscanql knows them by), one-to-many relationships are always encoded as arrays, and Scanners
+// that implement driver.Valuer are encoded as the result of Value (so that the Null types are
+// encoded as either their value or null). Types that implement json.Marshaler are encoded by
+// MarshalJSON.
+func appendJSON(b []byte, v reflect.Value, o *options) ([]byte, error) {
+	v = indirectValue(v)
+	if !v.IsValid() {
+		return append(b, "null"...), nil
+	}
+
+	if marshaler, ok := asJSONMarshaler(v); ok {
+		return appendMarshaled(b, marshaler)
+	}
+
+	switch kindOf(v.Type(), o) {
+	case scannerKind:
+		if !reflect.PointerTo(v.Type()).Implements(valuerType) && !v.Type().Implements(valuerType) {
+			return appendMarshaled(b, v.Interface())
+		}
+
+		value, err := driverValue(v)
+		if err != nil {
+			return nil, err
+		}
+
+		return appendMarshaled(b, value)
+
+	case oneToOneKind:
+		return appendJSONObject(b, v, o)
+
+	case oneToManyKind:
+		b = append(b, '[')
+
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				b = append(b, ',')
+			}
+
+			var err error
+			b, err = appendJSON(b, v.Index(i), o)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return append(b, ']'), nil
+	}
+
+	return appendMarshaled(b, v.Interface())
+}
+
+// appendJSONObject appends the JSON encoding of the provided struct (v) to b, as an object of
+// each of the fields that goscanql maps (in the order that they are declared).
+func appendJSONObject(b []byte, v reflect.Value, o *options) ([]byte, error) {
+	b = append(b, '{')
+	written := 0
+
+	for _, field := range structFields(v.Type(), o) {
+		name, ok := jsonName(field)
+		if !ok || !field.field.IsExported() {
+			continue
+		}
+
+		if written > 0 {
+			b = append(b, ',')
+		}
+
+		written++
+
+		var err error
+		b, err = appendMarshaled(b, name)
+		if err != nil {
+			return nil, err
+		}
+
+		b = append(b, ':')
+
+		b, err = appendJSON(b, v.FieldByIndex(field.field.Index), o)
+		if err != nil {
+			return nil, fmt.Errorf("goscanql: unable to encode field %s: %w", field.field.Name, err)
+		}
+	}
+
+	return append(b, '}'), nil
+}
+
+// jsonName returns the name that the provided field should be encoded with, which is the name of
+// its json tag, or otherwise the name that goscanql knows the field by. False is returned if the
+// field is excluded from JSON (i.e. tagged with `json:"-"`).
+func jsonName(field structField) (string, bool) {
+	raw := field.field.Tag.Get(jsonTag)
+	if raw == "-" {
+		return "", false
+	}
+
+	name, _, _ := strings.Cut(raw, ",")
+	if name == "" {
+		name = field.tag.name
+	}
+
+	return name, true
+}
+
+// asJSONMarshaler returns the provided value (v) as a json.Marshaler, if it (or a pointer to it)
+// implements the interface.
+func asJSONMarshaler(v reflect.Value) (json.Marshaler, bool) {
+	if v.Type().Implements(jsonMarshalerType) {
+		return v.Interface().(json.Marshaler), true
+	}
+
+	if v.CanAddr() && v.Addr().Type().Implements(jsonMarshalerType) {
+		return v.Addr().Interface().(json.Marshaler), true
+	}
+
+	return nil, false
+}
+
+// appendMarshaled appends the result of json.Marshal for the provided value to b.
+func appendMarshaled(b []byte, value interface{}) ([]byte, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(b, encoded...), nil
+}
+
+// streamJSON reads each row (with the provided next and scan functions) into a T (the provided
+// type), writing each root entity to the provided jsonArrayWriter as soon as it is complete. A
+// root entity is considered to be complete once a row of a different root entity is read, so the
+// rows must be ordered by the root entity.
+func streamJSON[T any](cols []string, next func() bool, scan func(...interface{}) error, jw *jsonArrayWriter, o *options) error {
+	var current *recordMap[T]
+	var currentHash string
+
+	flush := func() error {
+		if current == nil {
+			return nil
+		}
+
+		err := current.sort(o)
+		if err != nil {
+			return err
+		}
+
+		err = current.finalize(o)
+		if err != nil {
+			return err
+		}
+
+		return jw.writeEntities(reflect.ValueOf(current.entries), o)
+	}
+
+	for next() {
+		fields, err := scanEntry[T](cols, scan, o)
+		if err != nil {
+			return err
+		}
+
+		if fields.isNil() {
+			continue
+		}
+
+		hash := fields.getHash()
+
+		if current != nil && hash != currentHash {
+			err = flush()
+			if err != nil {
+				return err
+			}
+
+			current = nil
+		}
+
+		if current == nil {
+			current = newRecordMap[T](o)
+			currentHash = hash
+		}
+
+		err = current.merge(fields)
+		if err != nil {
+			return err
+		}
+	}
+
+	return flush()
+}
+
+// RowsToJSON will take the data in rows (*sql.Rows) and aggregate it into Ts (the provided type)
+// in the same way that RowsToStructs does, writing the result to the provided writer as a JSON
+// array.
+//
+// Fields are named by their json tag, or otherwise by the name that goscanql knows them by (e.g.
+// their sql tag), and the Null types are encoded as either their value or null.
+//
+// Where the WithOrderedRows option is provided, each root entity is written as soon as it is
+// complete rather than once every row has been read, so that the result doesn't need to be held
+// in memory. Otherwise (or where WithSortBy is provided) the entities are written once every row
+// has been aggregated.
+func RowsToJSON[T any](rows *sql.Rows, w io.Writer, opts ...Option) error {
+	var zero T
+
+	o := newOptions(opts)
+
+	err := validateType(zero, o)
+	if err != nil {
+		return err
+	}
+
+	jw := &jsonArrayWriter{w: w}
+
+	if o.orderedRows && len(o.sortBy) == 0 {
+		cols, err := rowsColumns(rows, reflect.TypeOf(zero), o)
+		if err != nil {
+			return err
+		}
+
+		err = streamJSON[T](cols, rows.Next, rows.Scan, jw, o)
+		if err != nil {
+			return err
+		}
+
+		err = rows.Err()
+		if err != nil {
+			return err
+		}
+
+		return jw.close()
+	}
+
+	entities, err := scanRows[T](rows, o)
+	if err != nil {
+		return err
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return err
+	}
+
+	err = jw.writeEntities(reflect.ValueOf(entities), o)
+	if err != nil {
+		return err
+	}
+
+	return jw.close()
+}
@@ -0,0 +1,211 @@
+package goscanql
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+type jsonTestColour struct {
+	Red   int `sql:"red" json:"r"`
+	Green int `sql:"green" json:"-"`
+}
+
+type jsonTestPet struct {
+	Name   string          `sql:"name"`
+	Colour *jsonTestColour `sql:"colour" json:"colour,omitempty"`
+}
+
+type jsonTestAccount struct {
+	ID          int           `sql:"id" json:"accountId"`
+	Nickname    NullString    `sql:"nickname"`
+	DateOfBirth time.Time     `sql:"date_of_birth"`
+	Pets        []jsonTestPet `sql:"pets"`
+	Aliases     []string      `sql:"alias"`
+	Ignored     string
+}
+
+// recordingWriter records each of the writes made to it.
+type recordingWriter struct {
+	writes []string
+}
+
+func (rw *recordingWriter) Write(p []byte) (int, error) {
+	rw.writes = append(rw.writes, string(p))
+	return len(p), nil
+}
+
+func newJSONTestRows() *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{"id", "nickname", "date_of_birth", "pets_name", "pets_colour_red", "pets_colour_green", "alias"})
+
+	rows.AddRow(1, "Duchess", time.Date(1978, 12, 30, 0, 0, 0, 0, time.UTC), "Babou", 255, 128, "Randy")
+	rows.AddRow(1, "Duchess", time.Date(1978, 12, 30, 0, 0, 0, 0, time.UTC), "Gustavo", nil, nil, "Randy")
+	rows.AddRow(2, nil, time.Date(1987, 4, 24, 0, 0, 0, 0, time.UTC), nil, nil, nil, nil)
+
+	return rows
+}
+
+const expectedJSONAccounts = `[` +
+	`{"accountId":1,"nickname":"Duchess","date_of_birth":"1978-12-30T00:00:00Z","pets":[{"name":"Babou","colour":{"r":255}},{"name":"Gustavo","colour":null}],"alias":["Randy"]},` +
+	`{"accountId":2,"nickname":null,"date_of_birth":"1987-04-24T00:00:00Z","pets":[],"alias":[]}` +
+	`]`
+
+func TestRowsToJSON(t *testing.T) {
+	tests := []struct {
+		name           string
+		opts           []Option
+		expectedWrites int
+	}{
+		{
+			name:           "GivenUnorderedRows_ThenWrittenOnceAggregated",
+			expectedWrites: 3,
+		},
+		{
+			name:           "GivenOrderedRows_ThenWrittenAsCompleted",
+			opts:           []Option{WithOrderedRows()},
+			expectedWrites: 3,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Arrange
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				panic(err)
+			}
+
+			mock.ExpectQuery("SELECT").WillReturnRows(newJSONTestRows())
+
+			rows, err := db.Query("SELECT")
+			if err != nil {
+				panic(err)
+			}
+
+			w := &recordingWriter{}
+
+			// Act
+			err = RowsToJSON[*jsonTestAccount](rows, w, test.opts...)
+
+			// Assert
+			assert.Nil(t, err)
+			assert.Len(t, w.writes, test.expectedWrites)
+
+			var buf bytes.Buffer
+			for _, write := range w.writes {
+				buf.WriteString(write)
+			}
+
+			assert.Equal(t, expectedJSONAccounts, buf.String())
+		})
+	}
+}
+
+func TestRowsToJSON_Streaming(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		panic(err)
+	}
+
+	mock.ExpectQuery("SELECT").WillReturnRows(newJSONTestRows())
+
+	rows, err := db.Query("SELECT")
+	if err != nil {
+		panic(err)
+	}
+
+	cols, err := rows.Columns()
+	if err != nil {
+		panic(err)
+	}
+
+	w := &recordingWriter{}
+
+	// record the number of writes that had been made before each row was read
+	written := make([]int, 0)
+	next := func() bool {
+		written = append(written, len(w.writes))
+		return rows.Next()
+	}
+
+	// Act
+	err = streamJSON[*jsonTestAccount](cols, next, rows.Scan, &jsonArrayWriter{w: w}, newOptions([]Option{WithOrderedRows()}))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []int{0, 0, 0, 1}, written)
+	assert.Len(t, w.writes, 2)
+}
+
+func TestRowsToJSON_RowError(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{
+			name: "GivenUnorderedRows_ThenErrorReturned",
+		},
+		{
+			name: "GivenOrderedRows_ThenErrorReturned",
+			opts: []Option{WithOrderedRows()},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Arrange
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				panic(err)
+			}
+
+			rowErr := errors.New("connection reset")
+
+			mock.ExpectQuery("SELECT").WillReturnRows(newJSONTestRows().RowError(1, rowErr))
+
+			rows, err := db.Query("SELECT")
+			if err != nil {
+				panic(err)
+			}
+
+			var buf bytes.Buffer
+
+			// Act
+			err = RowsToJSON[jsonTestAccount](rows, &buf, test.opts...)
+
+			// Assert
+			assert.Equal(t, rowErr, err)
+			assert.False(t, json.Valid(buf.Bytes()), "a truncated array shouldn't be valid JSON")
+		})
+	}
+}
+
+func TestRowsToJSON_Empty(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		panic(err)
+	}
+
+	mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	rows, err := db.Query("SELECT")
+	if err != nil {
+		panic(err)
+	}
+
+	var buf bytes.Buffer
+
+	// Act
+	err = RowsToJSON[jsonTestAccount](rows, &buf, WithOrderedRows())
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "[]", buf.String())
+}
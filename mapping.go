@@ -0,0 +1,204 @@
+package goscanql
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// KindValue is the FieldMapping kind of a field that is scanned as a single value.
+	KindValue = "value"
+
+	// KindScanner is the FieldMapping kind of a field that implements Scanner.
+	KindScanner = "scanner"
+
+	// KindOneToOne is the FieldMapping kind of a nested struct.
+	KindOneToOne = "one-to-one"
+
+	// KindOneToMany is the FieldMapping kind of a slice.
+	KindOneToMany = "one-to-many"
+)
+
+// mappingKinds maps each of the FieldMapping kinds to the fieldKind that it represents.
+var mappingKinds = map[string]fieldKind{
+	KindValue:     valueKind,
+	KindScanner:   scannerKind,
+	KindOneToOne:  oneToOneKind,
+	KindOneToMany: oneToManyKind,
+}
+
+// Mapping is an external definition of how struct types are mapped by goscanql, which can be
+// used in place of sql tags (e.g. for structs that are generated, and can't carry tags). A
+// Mapping can be loaded from YAML or JSON with LoadMapping, or built with NewMapping, and is
+// provided to goscanql with the WithMapping option.
+type Mapping struct {
+
+	// Types holds the TypeMapping of each struct type, keyed by the name of the type (either
+	// qualified by its package, e.g. "models.User", or not, e.g. "User").
+	Types map[string]TypeMapping `json:"types" yaml:"types"`
+}
+
+// TypeMapping holds the FieldMapping of each field of a struct type that is mapped by goscanql,
+// keyed by the name of the field. Fields that aren't included aren't mapped.
+type TypeMapping map[string]FieldMapping
+
+// FieldMapping describes how a single field of a struct type is mapped, and is equivalent to the
+// sql tag of the field.
+type FieldMapping struct {
+
+	// Column is the name of the column that the field is mapped to (or the prefix of its columns,
+	// where the field is a one-to-one or one-to-many relationship).
+	Column string `json:"column" yaml:"column"`
+
+	// Kind is the kind of the field (KindValue, KindScanner, KindOneToOne or KindOneToMany), which
+	// is validated against the type of the field. Kind is optional, as it is otherwise determined
+	// by the type of the field.
+	Kind string `json:"kind,omitempty" yaml:"kind,omitempty"`
+
+	// Key determines whether the field identifies the entity (equivalent to the key tag option).
+	Key bool `json:"key,omitempty" yaml:"key,omitempty"`
+
+	// Options holds any further tag options of the field, e.g. "multiset" or "orderby=name".
+	Options []string `json:"options,omitempty" yaml:"options,omitempty"`
+}
+
+// rawTag returns the equivalent sql tag of the FieldMapping.
+func (fm FieldMapping) rawTag() string {
+	parts := []string{fm.Column}
+
+	if fm.Key {
+		parts = append(parts, keyTagOption)
+	}
+
+	return strings.Join(append(parts, fm.Options...), ",")
+}
+
+// rawTag returns the equivalent sql tag of the field with the provided name, and whether the
+// field is mapped.
+func (tm TypeMapping) rawTag(name string) (string, bool) {
+	fm, ok := tm[name]
+	if !ok {
+		return "", false
+	}
+
+	return fm.rawTag(), true
+}
+
+// NewMapping creates a new (empty) Mapping, which can be built upon with Map and Field.
+func NewMapping() *Mapping {
+	return &Mapping{
+		Types: map[string]TypeMapping{},
+	}
+}
+
+// Map adds the provided TypeMapping to the Mapping as the mapping of the type of the provided
+// value (v), e.g. m.Map(User{}, ...), and returns the Mapping so that calls can be chained.
+func (m *Mapping) Map(v interface{}, tm TypeMapping) *Mapping {
+	m.Types[mappingTypeName(reflect.TypeOf(v))] = tm
+	return m
+}
+
+// Field adds the provided FieldMapping to the Mapping as the mapping of the named field of the
+// type of the provided value (v), e.g. m.Field(User{}, "ID", ...), and returns the Mapping so that
+// calls can be chained.
+func (m *Mapping) Field(v interface{}, name string, fm FieldMapping) *Mapping {
+	typeName := mappingTypeName(reflect.TypeOf(v))
+
+	if _, ok := m.Types[typeName]; !ok {
+		m.Types[typeName] = TypeMapping{}
+	}
+
+	m.Types[typeName][name] = fm
+	return m
+}
+
+// mappingTypeName returns the name that the provided type (t) is keyed by in a Mapping built with
+// NewMapping.
+func mappingTypeName(t reflect.Type) string {
+	return getPointerRootType(t).String()
+}
+
+// typeMapping returns the TypeMapping of the provided struct type (st), and whether there is one.
+func (m *Mapping) typeMapping(st reflect.Type) (TypeMapping, bool) {
+	if tm, ok := m.Types[st.String()]; ok {
+		return tm, true
+	}
+
+	tm, ok := m.Types[st.Name()]
+	return tm, ok
+}
+
+// validate returns an error if the TypeMapping of the provided type (t) (if there is one)
+// describes a field that the type doesn't have, doesn't provide a column for a field, or declares
+// a kind that doesn't match the type of the field (given the converters of o).
+func (m *Mapping) validate(t reflect.Type, o *options) error {
+	t = getPointerRootType(t)
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	tm, ok := m.typeMapping(t)
+	if !ok {
+		return nil
+	}
+
+	for name, fm := range tm {
+		field, ok := t.FieldByName(name)
+		if !ok || len(field.Index) != 1 {
+			return fmt.Errorf("goscanql: mapping of %s describes a field that doesn't exist (%s)", t.String(), name)
+		}
+
+		// an extra field collects the columns that aren't mapped, so has no column of its own
+		if fm.Column == "" && !parseTag(fm.rawTag()).has(extraTagOption) {
+			return fmt.Errorf("goscanql: mapping of %s.%s has no column", t.String(), name)
+		}
+
+		if fm.Kind == "" {
+			continue
+		}
+
+		kind, ok := mappingKinds[fm.Kind]
+		if !ok {
+			return fmt.Errorf("goscanql: mapping of %s.%s has an unknown kind (%s)", t.String(), name, fm.Kind)
+		}
+
+		if kind != kindOf(field.Type, o) {
+			return fmt.Errorf("goscanql: mapping of %s.%s declares kind %s, which doesn't match its type (%s)", t.String(), name, fm.Kind, field.Type.String())
+		}
+	}
+
+	return nil
+}
+
+// LoadMapping reads a Mapping from the provided reader, which may be in either YAML or JSON, for
+// example:
+//
+//	types:
+//	  User:
+//	    ID: {column: id, key: true}
+//	    Name: {column: name}
+//	    Pets: {column: pets, kind: one-to-many}
+//	  Pet:
+//	    Name: {column: name}
+func LoadMapping(r io.Reader) (*Mapping, error) {
+	m := NewMapping()
+
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+
+	err := decoder.Decode(m)
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("goscanql: mapping is empty")
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("goscanql: unable to load mapping: %w", err)
+	}
+
+	return m, nil
+}
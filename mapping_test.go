@@ -0,0 +1,303 @@
+package goscanql
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+// mappingTestPet and mappingTestAccount represent generated structs, which can't carry sql tags.
+type mappingTestPet struct {
+	Name   string
+	Colour *mappingTestColour
+}
+
+type mappingTestColour struct {
+	Red int
+}
+
+type mappingTestAccount struct {
+	ID       int
+	Email    string
+	Pets     []mappingTestPet
+	Internal string
+}
+
+const mappingTestYAML = `
+types:
+  mappingTestAccount:
+    ID: {column: id, key: true}
+    Email: {column: email}
+    Pets: {column: pets, kind: one-to-many, options: ["orderby=name desc"]}
+  goscanql.mappingTestPet:
+    Name: {column: name}
+    Colour: {column: colour}
+  mappingTestColour:
+    Red: {column: red}
+`
+
+const mappingTestJSON = `{
+	"types": {
+		"mappingTestAccount": {
+			"ID": {"column": "id", "key": true},
+			"Email": {"column": "email"},
+			"Pets": {"column": "pets", "kind": "one-to-many", "options": ["orderby=name desc"]}
+		},
+		"goscanql.mappingTestPet": {
+			"Name": {"column": "name"},
+			"Colour": {"column": "colour"}
+		},
+		"mappingTestColour": {
+			"Red": {"column": "red"}
+		}
+	}
+}`
+
+func mustLoadMapping(raw string) *Mapping {
+	m, err := LoadMapping(strings.NewReader(raw))
+	if err != nil {
+		panic(err)
+	}
+
+	return m
+}
+
+func TestRowsToStructsWithMapping(t *testing.T) {
+	tests := []struct {
+		name    string
+		mapping *Mapping
+	}{
+		{
+			name:    "GivenYAML",
+			mapping: mustLoadMapping(mappingTestYAML),
+		},
+		{
+			name:    "GivenJSON",
+			mapping: mustLoadMapping(mappingTestJSON),
+		},
+		{
+			name: "GivenBuilder",
+			mapping: NewMapping().
+				Map(mappingTestAccount{}, TypeMapping{
+					"ID":    {Column: "id", Key: true},
+					"Email": {Column: "email"},
+					"Pets":  {Column: "pets", Kind: KindOneToMany, Options: []string{"orderby=name desc"}},
+				}).
+				Field(mappingTestPet{}, "Name", FieldMapping{Column: "name"}).
+				Field(mappingTestPet{}, "Colour", FieldMapping{Column: "colour"}).
+				Field(&mappingTestColour{}, "Red", FieldMapping{Column: "red"}),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Arrange
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				panic(err)
+			}
+
+			rows := sqlmock.NewRows([]string{"id", "email", "pets_name", "pets_colour_red", "internal"})
+			rows.AddRow(1, "sterling.archer@isis.com", "Babou", 255, "a")
+			rows.AddRow(1, "archer@isis.com", "Gustavo", nil, "b")
+			rows.AddRow(2, "cheryl.tunt@isis.com", nil, nil, "c")
+
+			mock.ExpectQuery("SELECT").WillReturnRows(rows)
+
+			sqlRows, err := db.Query("SELECT")
+			if err != nil {
+				panic(err)
+			}
+
+			// Act
+			result, err := RowsToStructs[mappingTestAccount](sqlRows, WithMapping(test.mapping))
+
+			// Assert
+			assert.Nil(t, err)
+			assert.Equal(t, []mappingTestAccount{
+				{
+					ID:    1,
+					Email: "sterling.archer@isis.com",
+					Pets: []mappingTestPet{
+						{Name: "Gustavo"},
+						{Name: "Babou", Colour: &mappingTestColour{Red: 255}},
+					},
+				},
+				{
+					ID:    2,
+					Email: "cheryl.tunt@isis.com",
+				},
+			}, result)
+		})
+	}
+}
+
+func TestMapping_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       interface{}
+		mapping     *Mapping
+		expectedErr error
+	}{
+		{
+			name:    "GivenValidMapping_ThenNoError",
+			input:   mappingTestAccount{},
+			mapping: mustLoadMapping(mappingTestYAML),
+		},
+		{
+			name:  "GivenUnknownField_ThenErrorReturned",
+			input: mappingTestAccount{},
+			mapping: NewMapping().
+				Field(mappingTestAccount{}, "Identifier", FieldMapping{Column: "id"}),
+			expectedErr: fmt.Errorf("goscanql: mapping of goscanql.mappingTestAccount describes a field that doesn't exist (Identifier)"),
+		},
+		{
+			name:  "GivenNoColumn_ThenErrorReturned",
+			input: mappingTestAccount{},
+			mapping: NewMapping().
+				Field(mappingTestAccount{}, "ID", FieldMapping{Key: true}),
+			expectedErr: fmt.Errorf("goscanql: mapping of goscanql.mappingTestAccount.ID has no column"),
+		},
+		{
+			name:  "GivenMismatchedKind_ThenErrorReturned",
+			input: mappingTestAccount{},
+			mapping: NewMapping().
+				Field(mappingTestAccount{}, "Pets", FieldMapping{Column: "pets", Kind: KindOneToOne}),
+			expectedErr: fmt.Errorf("goscanql: mapping of goscanql.mappingTestAccount.Pets declares kind one-to-one, which doesn't match its type ([]goscanql.mappingTestPet)"),
+		},
+		{
+			name:  "GivenUnknownKind_ThenErrorReturned",
+			input: mappingTestAccount{},
+			mapping: NewMapping().
+				Field(mappingTestAccount{}, "Pets", FieldMapping{Column: "pets", Kind: "many-to-many"}),
+			expectedErr: fmt.Errorf("goscanql: mapping of goscanql.mappingTestAccount.Pets has an unknown kind (many-to-many)"),
+		},
+		{
+			name:  "GivenMappedUnsupportedField_ThenValidatorErrorReturned",
+			input: mappingTestValidation{},
+			mapping: NewMapping().
+				Field(mappingTestValidation{}, "Lookup", FieldMapping{Column: "lookup"}),
+			expectedErr: fmt.Errorf("maps are not supported (map[string]string), consider using a slice instead"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Act
+			err := validateType(test.input, newOptions([]Option{WithMapping(test.mapping)}))
+
+			// Assert
+			assert.Equal(t, test.expectedErr, err)
+		})
+	}
+}
+
+// mappingTestValidation has a field that goscanql can't map (but isn't mapped by default).
+type mappingTestValidation struct {
+	Lookup map[string]string
+}
+
+func TestLoadMapping_Errors(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expectedErr string
+	}{
+		{
+			name:        "GivenEmptyInput_ThenErrorReturned",
+			input:       "",
+			expectedErr: "goscanql: mapping is empty",
+		},
+		{
+			name:        "GivenUnknownProperty_ThenErrorReturned",
+			input:       "types:\n  User:\n    ID: {name: id}\n",
+			expectedErr: "goscanql: unable to load mapping: yaml: unmarshal errors:\n  line 3: field name not found in type goscanql.FieldMapping",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Act
+			_, err := LoadMapping(strings.NewReader(test.input))
+
+			// Assert
+			assert.EqualError(t, err, test.expectedErr)
+		})
+	}
+}
+
+func Test_RowsToStructsWithKeys(t *testing.T) {
+	type pet struct {
+		ID   int
+		Name string
+	}
+
+	type account struct {
+		ID   int
+		Name string
+		Pets []pet
+	}
+
+	mapping := NewMapping().
+		Map(account{}, TypeMapping{
+			"ID":   {Column: "id", Key: true},
+			"Name": {Column: "name"},
+			"Pets": {Column: "pets", Kind: KindOneToMany},
+		}).
+		Map(pet{}, TypeMapping{
+			"ID":   {Column: "id", Key: true},
+			"Name": {Column: "name"},
+		})
+
+	// Arrange
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		panic(err)
+	}
+
+	rows := sqlmock.NewRows([]string{"id", "name", "pets_id", "pets_name"})
+	for _, row := range [][]driver.Value{
+		{1, "Sterling Archer", 10, "Babou"},
+		{1, "Sterling Malory Archer", 10, "Babou the Ocelot"},
+		{1, "Sterling Archer", 11, "Gustavo"},
+	} {
+		rows.AddRow(row...)
+	}
+
+	mock.ExpectQuery("SELECT").WillReturnRows(rows)
+
+	sqlRows, err := db.Query("SELECT")
+	if err != nil {
+		panic(err)
+	}
+
+	// Act
+	result, err := RowsToStructs[account](sqlRows, WithMapping(mapping))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []account{
+		{
+			ID:   1,
+			Name: "Sterling Archer",
+			Pets: []pet{{ID: 10, Name: "Babou"}, {ID: 11, Name: "Gustavo"}},
+		},
+	}, result)
+}
+
+func TestValidateType_RejectsKeyTagOption(t *testing.T) {
+	type account struct {
+		ID   int    `sql:"id,key"`
+		Name string `sql:"name"`
+	}
+
+	// Act
+	err := validateType(account{}, nil)
+
+	// Assert
+	assert.EqualError(t, err, "goscanql: invalid tag of field goscanql.account.ID: the key option is only supported by a Mapping")
+}
@@ -0,0 +1,385 @@
+package goscanql
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// MapSpec describes the shape of the nested maps that RowsToMaps builds from a set of rows, in
+// place of a struct. The columns of each level are the columns that share its prefix (following
+// the same prefix convention as struct tags), and the columns that are not part of a child are
+// the values of the level itself, for example the columns id, name, pets_name and pets_colour_red
+// could be described as:
+//
+//	goscanql.MapSpec{
+//		Keys: []string{"id"},
+//		OneToMany: map[string]goscanql.MapSpec{
+//			"pets": {
+//				OneToOne: map[string]goscanql.MapSpec{"colour": {}},
+//			},
+//		},
+//	}
+type MapSpec struct {
+
+	// Keys are the names of the columns (relative to the prefix of the level) that identify an
+	// entity of the level, so that rows with the same keys are aggregated into the same entity.
+	// Where no keys are provided, every column of the level identifies the entity (as is the case
+	// for structs).
+	Keys []string
+
+	// OneToOne holds the spec of each one-to-one child of the level, keyed by its prefix.
+	OneToOne map[string]MapSpec
+
+	// OneToMany holds the spec of each one-to-many child of the level, keyed by its prefix. Where
+	// a column is named after the prefix itself (rather than prefixed by it), the child is read as
+	// a slice of the column's values (in the same way as e.g. []string).
+	OneToMany map[string]MapSpec
+}
+
+// mapKey is a Scanner that holds the value of a key column of a MapSpec, which forms part of the
+// identity of the entity that it belongs to.
+type mapKey struct {
+	value interface{}
+}
+
+func (mk *mapKey) Scan(value interface{}) error {
+	// drivers may reuse the memory of byte slices between rows, so they must be copied
+	if b, ok := value.([]byte); ok {
+		value = append([]byte{}, b...)
+	}
+
+	mk.value = value
+	return nil
+}
+
+func (mk *mapKey) ID() []byte {
+	return []byte(fmt.Sprintf("%#v", mk.value))
+}
+
+// mapValue is a Scanner that holds the value of a non-key column of a MapSpec, which doesn't
+// form part of the identity of the entity that it belongs to.
+type mapValue struct {
+	mapKey
+}
+
+func (mv *mapValue) ID() []byte {
+	return nil
+}
+
+var (
+	mapKeyType   = reflect.TypeOf(mapKey{})
+	mapValueType = reflect.TypeOf(mapValue{})
+)
+
+// mapLevel represents a single level of the maps built by RowsToMaps, from which the struct type
+// that the level is scanned into is built.
+type mapLevel struct {
+
+	// fields are the fields of the struct type of the level, in the order that they are declared.
+	fields []mapField
+}
+
+// mapField represents a single field of the struct type of a mapLevel.
+type mapField struct {
+
+	// name is the name of the column (or the prefix of the child) that the field is mapped to.
+	name string
+
+	// kind is the way in which goscanql treats the field.
+	kind fieldKind
+
+	// key determines whether the field identifies the entity (for value fields).
+	key bool
+
+	// child is the level of a one-to-one or one-to-many child (nil for slices of values).
+	child *mapLevel
+}
+
+// newMapLevel builds the mapLevel of the provided spec from the provided columns (each of which
+// is relative to the prefix of the level).
+func newMapLevel(spec MapSpec, columns []string, prefix string) (*mapLevel, error) {
+	level := &mapLevel{}
+
+	childColumns := map[string][]string{}
+	values := map[string]bool{}
+
+	for _, column := range columns {
+		child := childPrefix(column, spec)
+
+		switch {
+		case child == "":
+			level.fields = append(level.fields, mapField{
+				name: column,
+				kind: scannerKind,
+				key:  len(spec.Keys) == 0 || containsString(spec.Keys, column),
+			})
+		case child == column:
+			values[child] = true
+		default:
+			childColumns[child] = append(childColumns[child], trimPrefix(column, child))
+		}
+	}
+
+	for _, key := range spec.Keys {
+		if !level.hasColumn(key) {
+			return nil, fmt.Errorf("goscanql: key column %s not found", buildReferenceName(prefix, key))
+		}
+	}
+
+	for _, child := range sortedKeys(spec.OneToOne) {
+		childLevel, err := newMapLevel(spec.OneToOne[child], childColumns[child], buildReferenceName(prefix, child))
+		if err != nil {
+			return nil, err
+		}
+
+		level.fields = append(level.fields, mapField{name: child, kind: oneToOneKind, child: childLevel})
+	}
+
+	for _, child := range sortedKeys(spec.OneToMany) {
+		if values[child] {
+			level.fields = append(level.fields, mapField{name: child, kind: oneToManyKind})
+			continue
+		}
+
+		childLevel, err := newMapLevel(spec.OneToMany[child], childColumns[child], buildReferenceName(prefix, child))
+		if err != nil {
+			return nil, err
+		}
+
+		level.fields = append(level.fields, mapField{name: child, kind: oneToManyKind, child: childLevel})
+	}
+
+	return level, nil
+}
+
+// structType builds the struct type that the level is scanned into, where each value is held by
+// a mapKey (or a mapValue where it doesn't identify the entity), one-to-one children are held by
+// pointers and one-to-many children by slices.
+func (ml *mapLevel) structType() reflect.Type {
+	structFields := make([]reflect.StructField, len(ml.fields))
+
+	for i, field := range ml.fields {
+		var t reflect.Type
+
+		switch {
+		case field.kind == oneToOneKind:
+			t = reflect.PointerTo(field.child.structType())
+		case field.kind == oneToManyKind && field.child != nil:
+			t = reflect.SliceOf(field.child.structType())
+		case field.kind == oneToManyKind:
+			t = reflect.SliceOf(mapKeyType)
+		case field.key:
+			t = mapKeyType
+		default:
+			t = mapValueType
+		}
+
+		structFields[i] = reflect.StructField{
+			Name: fmt.Sprintf("F%d", i),
+			Type: t,
+			Tag:  reflect.StructTag(fmt.Sprintf("%s:%q", scanqlTag, field.name)),
+		}
+	}
+
+	return reflect.StructOf(structFields)
+}
+
+// toMap converts the provided value (v) of the level's struct type into a map, keyed by the names
+// of the columns (and the prefixes of the children).
+func (ml *mapLevel) toMap(v reflect.Value) map[string]interface{} {
+	v = indirectValue(v)
+	if !v.IsValid() {
+		return nil
+	}
+
+	m := make(map[string]interface{}, len(ml.fields))
+
+	for i, field := range ml.fields {
+		fieldValue := v.Field(i)
+
+		switch field.kind {
+		case oneToOneKind:
+			if child := field.child.toMap(fieldValue); child != nil {
+				m[field.name] = child
+				continue
+			}
+
+			m[field.name] = nil
+
+		case oneToManyKind:
+			children := make([]interface{}, fieldValue.Len())
+
+			for j := range children {
+				if field.child == nil {
+					children[j] = scannedValue(fieldValue.Index(j))
+					continue
+				}
+
+				children[j] = field.child.toMap(fieldValue.Index(j))
+			}
+
+			m[field.name] = children
+
+		default:
+			m[field.name] = scannedValue(fieldValue)
+		}
+	}
+
+	return m
+}
+
+// hasColumn returns true if the level has a value column with the provided name.
+func (ml *mapLevel) hasColumn(name string) bool {
+	for _, field := range ml.fields {
+		if field.kind == scannerKind && field.name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// scannedValue returns the value held by the provided mapKey (or mapValue).
+func scannedValue(v reflect.Value) interface{} {
+	switch scanned := v.Addr().Interface().(type) {
+	case *mapKey:
+		return scanned.value
+	case *mapValue:
+		return scanned.value
+	}
+
+	return nil
+}
+
+// validateMapColumns ensures that each of the provided columns can be mapped to a field of the
+// struct types built by newMapLevel, where each column is used as the sql tag of its field. So
+// columns must be distinct (regardless of case where o matches them so), and their names mustn't
+// be read as tag options or as the exclusion of the field.
+func validateMapColumns(cols []string, o *options) error {
+	seen := make(map[string]string, len(cols))
+
+	for _, col := range cols {
+		if col == "-" {
+			return fmt.Errorf("goscanql: column %q can't be mapped by RowsToMaps, alias it to another name", col)
+		}
+
+		if parts, err := splitTag(col); err != nil || len(parts) != 1 {
+			return fmt.Errorf("goscanql: column %q can't be mapped by RowsToMaps, alias it to a name without commas, quotes or parentheses", col)
+		}
+
+		if other, ok := seen[o.columnKey(col)]; ok {
+			return fmt.Errorf("goscanql: column %q is selected more than once (as %q and %q), alias the columns to tell them apart", col, other, col)
+		}
+
+		seen[o.columnKey(col)] = col
+	}
+
+	return nil
+}
+
+// childPrefix returns the (longest) prefix of the children of the provided spec that the
+// provided column belongs to, or an empty string if it belongs to none of them. Columns named
+// after a one-to-many child (rather than prefixed by it) are the values of the child, so the
+// column itself is returned.
+func childPrefix(column string, spec MapSpec) string {
+	longest := ""
+
+	for _, children := range []map[string]MapSpec{spec.OneToOne, spec.OneToMany} {
+		for prefix := range children {
+			if len(prefix) > len(longest) && strings.HasPrefix(column, prefix+"_") {
+				longest = prefix
+			}
+		}
+	}
+
+	if _, ok := spec.OneToMany[column]; ok && longest == "" {
+		return column
+	}
+
+	return longest
+}
+
+// sortedKeys returns the keys of the provided map in order.
+func sortedKeys(m map[string]MapSpec) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+// scanValues reads each row (with the provided next and scan functions) into a new value of the
+// provided struct type (t), and merges them in the same way that a recordMap does, returning the
+// resulting slice of *t.
+func scanValues(cols []string, next func() bool, scan func(...interface{}) error, t reflect.Type, o *options) (reflect.Value, error) {
+	entries := reflect.New(reflect.SliceOf(reflect.PointerTo(t)))
+	hashTable := recordList{}
+	ctx := newMergeContext(o)
+
+	for next() {
+		entry := reflect.New(reflect.PointerTo(t))
+
+		fields, err := newFields(entry.Interface(), o)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		err = fields.scan(cols, scan)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		ctx.row++
+
+		rv := reflect.ValueOf(fields.obj).Elem()
+
+		err = hashTable.merge(fields, &rv, entries.Interface(), ctx)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+	}
+
+	return entries.Elem(), nil
+}
+
+// RowsToMaps will take the data in rows (*sql.Rows) as input and return a slice of nested maps as
+// the result, without the need for a struct. The provided MapSpec declares the one-to-one and
+// one-to-many children (by prefix), and the key columns of each level. Rows are aggregated in the
+// same way as they are for structs, where one-to-one children are held as nested maps (or nil)
+// and one-to-many children as slices ([]interface{}) of nested maps. Each column must have a
+// distinct name without commas, quotes or parentheses (so expressions should be aliased).
+func RowsToMaps(rows *sql.Rows, spec MapSpec, opts ...Option) ([]map[string]interface{}, error) {
+	o := newOptions(opts)
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	err = validateMapColumns(cols, o)
+	if err != nil {
+		return nil, err
+	}
+
+	level, err := newMapLevel(spec, cols, "")
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := scanValues(cols, rows.Next, rows.Scan, level.structType(), o)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]map[string]interface{}, entries.Len())
+	for i := range result {
+		result[i] = level.toMap(entries.Index(i))
+	}
+
+	return result, nil
+}
@@ -0,0 +1,176 @@
+package goscanql
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRowsToMaps(t *testing.T) {
+	columns := []string{"id", "email", "pets_name", "pets_colour_red", "alias"}
+
+	tests := []struct {
+		name        string
+		spec        MapSpec
+		rows        [][]interface{}
+		expected    []map[string]interface{}
+		expectedErr error
+	}{
+		{
+			name: "GivenNestedSpec_ThenNestedMapsReturned",
+			spec: MapSpec{
+				Keys: []string{"id"},
+				OneToMany: map[string]MapSpec{
+					"pets": {
+						OneToOne: map[string]MapSpec{"colour": {}},
+					},
+					"alias": {},
+				},
+			},
+			rows: [][]interface{}{
+				{1, "sterling.archer@isis.com", "Babou", 255, "Randy"},
+				{1, "sterling.archer@isis.com", "Babou", 255, "Duchess"},
+				{1, "sterling.archer@isis.com", "Gustavo", nil, "Randy"},
+				{1, "archer@isis.com", "Gustavo", nil, "Duchess"},
+				{2, "cheryl.tunt@isis.com", nil, nil, nil},
+				{nil, nil, nil, nil, nil},
+			},
+			expected: []map[string]interface{}{
+				{
+					"id":    int64(1),
+					"email": "sterling.archer@isis.com",
+					"pets": []interface{}{
+						map[string]interface{}{"name": "Babou", "colour": map[string]interface{}{"red": int64(255)}},
+						map[string]interface{}{"name": "Gustavo", "colour": nil},
+					},
+					"alias": []interface{}{"Randy", "Duchess"},
+				},
+				{
+					"id":    int64(2),
+					"email": "cheryl.tunt@isis.com",
+					"pets":  []interface{}{},
+					"alias": []interface{}{},
+				},
+			},
+		},
+		{
+			name: "GivenNoKeys_ThenEveryColumnIdentifiesEntity",
+			spec: MapSpec{},
+			rows: [][]interface{}{
+				{1, "sterling.archer@isis.com", "Babou", 255, "Randy"},
+				{1, "archer@isis.com", "Babou", 255, "Randy"},
+				{1, "archer@isis.com", "Babou", 255, "Randy"},
+			},
+			expected: []map[string]interface{}{
+				{"id": int64(1), "email": "sterling.archer@isis.com", "pets_name": "Babou", "pets_colour_red": int64(255), "alias": "Randy"},
+				{"id": int64(1), "email": "archer@isis.com", "pets_name": "Babou", "pets_colour_red": int64(255), "alias": "Randy"},
+			},
+		},
+		{
+			name: "GivenUnknownKey_ThenErrorReturned",
+			spec: MapSpec{
+				OneToMany: map[string]MapSpec{
+					"pets": {Keys: []string{"id"}},
+				},
+			},
+			expectedErr: fmt.Errorf("goscanql: key column pets_id not found"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Arrange
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				panic(err)
+			}
+
+			mockRows := sqlmock.NewRows(columns)
+			for _, row := range test.rows {
+				values := make([]driver.Value, len(row))
+				for i, value := range row {
+					values[i] = value
+				}
+
+				mockRows.AddRow(values...)
+			}
+
+			mock.ExpectQuery("SELECT").WillReturnRows(mockRows)
+
+			rows, err := db.Query("SELECT")
+			if err != nil {
+				panic(err)
+			}
+
+			// Act
+			result, err := RowsToMaps(rows, test.spec)
+
+			// Assert
+			assert.Equal(t, test.expectedErr, err)
+			assert.Equal(t, test.expected, result)
+		})
+	}
+}
+
+func TestRowsToMaps_InvalidColumns(t *testing.T) {
+	tests := []struct {
+		name        string
+		columns     []string
+		opts        []Option
+		expectedErr error
+	}{
+		{
+			name:        "GivenDuplicateColumns_ThenErrorReturned",
+			columns:     []string{"id", "name", "id"},
+			expectedErr: fmt.Errorf("goscanql: column \"id\" is selected more than once (as \"id\" and \"id\"), alias the columns to tell them apart"),
+		},
+		{
+			name:        "GivenColumnsDifferingByCaseWhenCaseInsensitive_ThenErrorReturned",
+			columns:     []string{"id", "ID"},
+			opts:        []Option{WithCaseInsensitiveColumns()},
+			expectedErr: fmt.Errorf("goscanql: column \"ID\" is selected more than once (as \"id\" and \"ID\"), alias the columns to tell them apart"),
+		},
+		{
+			name:        "GivenColumnWithComma_ThenErrorReturned",
+			columns:     []string{"id", "COALESCE(a,b)", "total,sum"},
+			expectedErr: fmt.Errorf("goscanql: column \"total,sum\" can't be mapped by RowsToMaps, alias it to a name without commas, quotes or parentheses"),
+		},
+		{
+			name:        "GivenColumnWithUnbalancedParenthesis_ThenErrorReturned",
+			columns:     []string{"id", "count("},
+			expectedErr: fmt.Errorf("goscanql: column \"count(\" can't be mapped by RowsToMaps, alias it to a name without commas, quotes or parentheses"),
+		},
+		{
+			name:        "GivenDashColumn_ThenErrorReturned",
+			columns:     []string{"id", "-"},
+			expectedErr: fmt.Errorf("goscanql: column \"-\" can't be mapped by RowsToMaps, alias it to another name"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Arrange
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				panic(err)
+			}
+
+			mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows(test.columns))
+
+			rows, err := db.Query("SELECT")
+			if err != nil {
+				panic(err)
+			}
+
+			// Act
+			result, err := RowsToMaps(rows, MapSpec{}, test.opts...)
+
+			// Assert
+			assert.Equal(t, test.expectedErr, err)
+			assert.Nil(t, result)
+		})
+	}
+}
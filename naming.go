@@ -0,0 +1,34 @@
+package goscanql
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NameMapper represents a function that produces a column name from the name of a struct
+// field. See WithNameMapper.
+type NameMapper func(fieldName string) string
+
+// SnakeCase is a NameMapper that converts a field name to snake case, e.g. DateOfBirth would
+// become date_of_birth and UserID would become user_id.
+func SnakeCase(fieldName string) string {
+	runes := []rune(fieldName)
+	b := strings.Builder{}
+
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			prevLower := unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+
+			// start a new word when moving from lower to upper case (fooBar), or at the end of
+			// an acronym (HTTPServer)
+			if prevLower || (nextLower && unicode.IsUpper(runes[i-1])) {
+				b.WriteRune('_')
+			}
+		}
+
+		b.WriteRune(unicode.ToLower(r))
+	}
+
+	return b.String()
+}
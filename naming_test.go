@@ -0,0 +1,61 @@
+package goscanql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnakeCase(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "SingleWord",
+			input:    "Name",
+			expected: "name",
+		},
+		{
+			name:     "MultipleWords",
+			input:    "DateOfBirth",
+			expected: "date_of_birth",
+		},
+		{
+			name:     "TrailingAcronym",
+			input:    "UserID",
+			expected: "user_id",
+		},
+		{
+			name:     "LeadingAcronym",
+			input:    "HTTPServer",
+			expected: "http_server",
+		},
+		{
+			name:     "SingleAcronym",
+			input:    "ID",
+			expected: "id",
+		},
+		{
+			name:     "Digits",
+			input:    "Address2Line",
+			expected: "address2_line",
+		},
+		{
+			name:     "AlreadySnakeCase",
+			input:    "already_snake",
+			expected: "already_snake",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Act
+			result := SnakeCase(test.input)
+
+			// Assert
+			assert.Equal(t, test.expected, result)
+		})
+	}
+}
@@ -0,0 +1,325 @@
+package goscanql
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Option represents a single configuration that can be provided to goscanql to alter the
+// way in which rows are mapped to structs.
+type Option func(*options)
+
+// options holds the configuration that has been built up from the Options provided by the
+// user. A nil *options is valid and represents goscanql's default behaviour.
+type options struct {
+
+	// nameMapper (when set) is used to produce column names for exported fields that don't
+	// have an sql tag.
+	nameMapper NameMapper
+
+	// caseInsensitive determines whether columns should be matched to fields regardless of
+	// the case of either.
+	caseInsensitive bool
+
+	// identityMap determines whether entities referenced by pointers should be shared across
+	// the result.
+	identityMap bool
+
+	// sortBy holds the keys that the root entities should be sorted by.
+	sortBy []string
+
+	// dialect is the Dialect that statements should be built in (nil if not provided).
+	dialect Dialect
+
+	// batchSize is the maximum number of rows inserted by a single statement (0 if not provided).
+	batchSize int
+
+	// updateKeys holds the columns that identify the rows updated by UpdateStatement.
+	updateKeys []string
+
+	// emptyCSVStrings determines whether the empty cells of a CSV are read as empty strings
+	// (rather than as NULL) by FromCSV.
+	emptyCSVStrings bool
+
+	// orderedRows determines whether the rows are known to be ordered by their root entity.
+	orderedRows bool
+
+	// mapping (when set) describes how struct types are mapped, in place of their sql tags.
+	mapping *Mapping
+
+	// validateColumnTypes determines whether the types of the columns should be validated against
+	// the fields that they are scanned into before any rows are scanned.
+	validateColumnTypes bool
+
+	// notNull determines whether every field that can't hold null should be treated as though it
+	// were tagged with the notnull option.
+	notNull bool
+
+	// converters holds the converters provided by WithConverter, keyed by the type that they
+	// convert to (nil if none were provided).
+	converters map[reflect.Type]converter
+
+	// timeLocation (when set) is the location that every time is converted to once scanned.
+	timeLocation *time.Location
+
+	// timeLayouts holds the layouts that string values of time columns are parsed with (nil if
+	// not provided).
+	timeLayouts []string
+
+	// unixTime is the unit of integer values of time columns (0 if they aren't accepted).
+	unixTime time.Duration
+
+	// plans caches the typePlan of each type that has been scanned into with the options.
+	plans sync.Map
+}
+
+// newOptions builds a new options from the provided Options.
+func newOptions(opts []Option) *options {
+	o := &options{}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// WithNameMapper returns an Option that will map exported struct fields that don't have an
+// sql tag to a column name using the provided NameMapper (e.g. SnakeCase). Fields can still
+// be excluded explicitly by tagging them with `sql:"-"`.
+func WithNameMapper(mapper NameMapper) Option {
+	return func(o *options) {
+		o.nameMapper = mapper
+	}
+}
+
+// WithCaseInsensitiveColumns returns an Option that will match columns to fields regardless
+// of case, e.g. a column named "Date_Of_Birth" would be matched to a field tagged with
+// `sql:"date_of_birth"`. Types with fields whose columns only differ by case are rejected, as
+// the columns couldn't be told apart.
+func WithCaseInsensitiveColumns() Option {
+	return func(o *options) {
+		o.caseInsensitive = true
+	}
+}
+
+// WithIdentityMap returns an Option that will cause pointers to equal entities (e.g. fields of
+// type *Colour or []*Tag) to point to a single shared instance across the whole result, rather
+// than a separate copy being allocated wherever the entity appears. Entities are considered equal
+// when they are of the same type and have the same hash.
+func WithIdentityMap() Option {
+	return func(o *options) {
+		o.identityMap = true
+	}
+}
+
+// WithSortBy returns an Option that will sort the root entities by the provided keys once all
+// of the rows have been aggregated. Each key is the name of a field, optionally followed by a
+// direction (asc or desc), e.g. "name desc". Fields of one-to-one relationships can be sorted by
+// using their full name, e.g. "colour_name". Keys that don't name a field of the root entities
+// are rejected before any rows are read.
+//
+// Entities that are equal by every key remain in the order that they were first seen.
+func WithSortBy(keys ...string) Option {
+	return func(o *options) {
+		o.sortBy = keys
+	}
+}
+
+// WithDialect returns an Option that will build statements in the provided Dialect (e.g. to
+// quote identifiers and number placeholders as the database expects).
+func WithDialect(dialect Dialect) Option {
+	return func(o *options) {
+		o.dialect = dialect
+	}
+}
+
+// getDialect returns the Dialect that statements should be built in.
+func (o *options) getDialect() Dialect {
+	if o == nil || o.dialect == nil {
+		return defaultDialect{}
+	}
+
+	return o.dialect
+}
+
+// WithBatchSize returns an Option that will limit the number of rows inserted by a single
+// statement of SaveGraph to the provided size.
+func WithBatchSize(size int) Option {
+	return func(o *options) {
+		o.batchSize = size
+	}
+}
+
+// getBatchSize returns the maximum number of rows that should be inserted by a single statement.
+func (o *options) getBatchSize() int {
+	if o == nil || o.batchSize <= 0 {
+		return defaultBatchSize
+	}
+
+	return o.batchSize
+}
+
+// WithUpdateKeys returns an Option that provides the columns (e.g. "id") that identify the rows
+// to be updated by UpdateStatement.
+func WithUpdateKeys(columns ...string) Option {
+	return func(o *options) {
+		o.updateKeys = columns
+	}
+}
+
+// WithEmptyCSVStrings returns an Option that will cause FromCSV to read empty cells as empty
+// strings, rather than as NULL.
+func WithEmptyCSVStrings() Option {
+	return func(o *options) {
+		o.emptyCSVStrings = true
+	}
+}
+
+// WithOrderedRows returns an Option that declares that the rows are ordered by their root entity
+// (e.g. the query is ordered by the root's key), so that all of the rows of a root entity are
+// adjacent. This allows RowsToJSON to write each root entity as soon as it is complete.
+func WithOrderedRows() Option {
+	return func(o *options) {
+		o.orderedRows = true
+	}
+}
+
+// WithMapping returns an Option that will map the struct types described by the provided Mapping
+// using the Mapping rather than their sql tags. Struct types that the Mapping doesn't describe are
+// still mapped by their tags.
+func WithMapping(mapping *Mapping) Option {
+	return func(o *options) {
+		o.mapping = mapping
+	}
+}
+
+// WithColumnTypeValidation returns an Option that will compare the types of the columns (as
+// reported by rows.ColumnTypes) with the fields that they are scanned into before any rows are
+// scanned, e.g. to catch schema drift in integration tests. An error describing every mismatch is
+// returned, where a mismatch is either:
+//
+//   - a nullable column mapped to a field of the root entity that can't hold null (e.g. an int),
+//     where null would otherwise silently become the zero value
+//   - a column whose values would lose their meaning in the field, e.g. float64 into a string
+//
+// The columns of children aren't checked for nullability, as null values are how goscanql
+// detects that a child is nil (e.g. where it is LEFT JOINed).
+func WithColumnTypeValidation() Option {
+	return func(o *options) {
+		o.validateColumnTypes = true
+	}
+}
+
+// WithNotNull returns an Option that will cause a ScanError (naming the column and the field) to be
+// returned where a null value is scanned into a field that can't hold null, i.e. any field that
+// isn't a pointer or a Scanner. This is equivalent to tagging every such field with the notnull
+// option, e.g. `sql:"age,notnull"`.
+//
+// Entities whose columns are all null are still considered to be nil (e.g. where they are LEFT
+// JOINed), so are unaffected.
+func WithNotNull() Option {
+	return func(o *options) {
+		o.notNull = true
+	}
+}
+
+// isNotNull returns true if the field with the provided tag (t) must not be scanned from a null
+// column, given that it can't hold null.
+func (o *options) isNotNull(t tag) bool {
+	return t.has(notNullTagOption) || o != nil && o.notNull
+}
+
+// fieldName returns the name that goscanql knows the i'th field of the provided struct type (st)
+// by, and whether the field is to be mapped by goscanql at all.
+func (o *options) fieldName(st reflect.Type, i int) (string, bool) {
+	t, ok := o.fieldTag(st, i)
+	return t.name, ok
+}
+
+// fieldTag returns the parsed sql tag of the i'th field of the provided struct type (st), and
+// whether the field is to be mapped by goscanql at all. Where the struct type is described by the
+// Mapping of o, the tag is taken from the Mapping instead. If the field has no name of its own
+// (either because it has no tag, or the tag only has options), its name is provided by the
+// NameMapper (if any).
+func (o *options) fieldTag(st reflect.Type, i int) (tag, bool) {
+	f := st.Field(i)
+
+	// a Presence is filled in by goscanql, rather than mapped to a column
+	if f.Type == presenceType {
+		return tag{}, false
+	}
+
+	raw, tagged := o.rawFieldTag(st, i)
+	if raw == "-" {
+		return tag{}, false
+	}
+
+	t := parseTag(raw)
+
+	// an extra field collects the columns that aren't mapped, rather than being mapped to a column
+	if t.has(extraTagOption) {
+		return tag{}, false
+	}
+
+	if t.name == "" && o != nil && o.nameMapper != nil && f.IsExported() {
+		t.name = o.nameMapper(f.Name)
+		return t, true
+	}
+
+	if tagged {
+		return t, true
+	}
+
+	return t, false
+}
+
+// rawFieldTag returns the raw sql tag of the i'th field of the provided struct type (st) (or its
+// equivalent, where the struct type is described by the Mapping of o), and whether it has one.
+func (o *options) rawFieldTag(st reflect.Type, i int) (string, bool) {
+	raw, tagged := st.Field(i).Tag.Lookup(scanqlTag)
+
+	if o != nil && o.mapping != nil {
+		if fields, ok := o.mapping.typeMapping(st); ok {
+			raw, tagged = fields.rawTag(st.Field(i).Name)
+		}
+	}
+
+	return raw, tagged
+}
+
+// isKey returns true if the i'th field of the provided struct type (st) is marked as a key by the
+// Mapping of o. Keys are only taken from a Mapping, as validateTags rejects the key option in sql
+// tags.
+func (o *options) isKey(st reflect.Type, i int) bool {
+	if o == nil || o.mapping == nil {
+		return false
+	}
+
+	fields, ok := o.mapping.typeMapping(st)
+	if !ok {
+		return false
+	}
+
+	raw, ok := fields.rawTag(st.Field(i).Name)
+	return ok && parseTag(raw).has(keyTagOption)
+}
+
+// isExtra returns true if the i'th field of the provided struct type (st) is tagged with the extra
+// option.
+func (o *options) isExtra(st reflect.Type, i int) bool {
+	raw, _ := o.rawFieldTag(st, i)
+	return raw != "-" && parseTag(raw).has(extraTagOption)
+}
+
+// columnKey returns the key that a column (or field) name should be looked up by when matching
+// columns to fields.
+func (o *options) columnKey(name string) string {
+	if o == nil || !o.caseInsensitive {
+		return name
+	}
+
+	return strings.ToLower(name)
+}
@@ -0,0 +1,183 @@
+package goscanql
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// features is a set of the optional features of goscanql that a fields (or any of its children)
+// uses, so that the passes of a scan that support a feature can be skipped where it isn't used.
+type features uint8
+
+const (
+	// notNullFeature is used by fields that must not be scanned from a null column.
+	notNullFeature features = 1 << iota
+
+	// defaultFeature is used by fields that have a default value.
+	defaultFeature
+
+	// presenceFeature is used by entities that have a Presence.
+	presenceFeature
+
+	// extraFeature is used by entities that have an extra field.
+	extraFeature
+
+	// afterScanFeature is used by entities that implement AfterScanner.
+	afterScanFeature
+)
+
+// has returns true if any of the provided features (fs) are in the set.
+func (f features) has(fs features) bool {
+	return f&fs != 0
+}
+
+// typePlan holds everything that goscanql needs to know about a type in order to scan into it,
+// which is worked out once per type (for a set of options) rather than once per row.
+type typePlan struct {
+
+	// converter is the converter of the type (nil if it has none).
+	converter converter
+
+	// features holds the features that the type itself uses (not including its children).
+	features features
+
+	// fields holds the plan of each field of a struct type that is mapped to a column.
+	fields []fieldPlan
+
+	// presence is the index of the Presence field of a struct type (-1 if it has none).
+	presence int
+
+	// extras is the index of the extra field of a struct type (-1 if it has none).
+	extras int
+}
+
+// fieldPlan holds everything that goscanql needs to know about a single mapped field.
+type fieldPlan struct {
+
+	// index is the index of the field within its struct.
+	index int
+
+	// name is the name of the field's column (relative to its entity).
+	name string
+
+	// goName is the name that the field is known by in Go, e.g. User.Age.
+	goName string
+
+	// kind is the way in which goscanql treats the field.
+	kind fieldKind
+
+	// converter is the converter of the field's type (nil if it has none).
+	converter converter
+
+	// key determines whether the field identifies its entity.
+	key bool
+
+	// notNull determines whether the field must not be scanned from a null column.
+	notNull bool
+
+	// defaultValue is the raw default value of the field (where hasDefault is true).
+	defaultValue string
+
+	// hasDefault determines whether the field has a default value.
+	hasDefault bool
+
+	// multiset determines whether duplicate children of a one-to-many field are preserved.
+	multiset bool
+}
+
+// planOf returns the typePlan of the provided (non-pointer) type (t), which is cached by o (where
+// o isn't nil).
+func (o *options) planOf(t reflect.Type) *typePlan {
+	if o == nil {
+		return newTypePlan(t, o)
+	}
+
+	if plan, ok := o.plans.Load(t); ok {
+		return plan.(*typePlan)
+	}
+
+	plan, _ := o.plans.LoadOrStore(t, newTypePlan(t, o))
+	return plan.(*typePlan)
+}
+
+// newTypePlan works out the typePlan of the provided (non-pointer) type (t), given the options
+// (o), which may be nil.
+func newTypePlan(t reflect.Type, o *options) *typePlan {
+	plan := &typePlan{presence: -1, extras: -1}
+
+	if c, ok := o.converterFor(t); ok {
+		plan.converter = c
+		return plan
+	}
+
+	if t.Kind() != reflect.Struct {
+		return plan
+	}
+
+	if reflect.PointerTo(t).Implements(afterScannerType) || t.Implements(afterScannerType) {
+		plan.features |= afterScanFeature
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		if field.Type == presenceType {
+			plan.presence = i
+			plan.features |= presenceFeature
+			continue
+		}
+
+		if o.isExtra(t, i) {
+			plan.extras = i
+			plan.features |= extraFeature
+			continue
+		}
+
+		fieldTag, ok := o.fieldTag(t, i)
+		if !ok {
+			continue
+		}
+
+		root := getPointerRootType(field.Type)
+
+		fp := fieldPlan{
+			index:    i,
+			name:     fieldTag.name,
+			goName:   goFieldName(t, field),
+			kind:     kindOf(root, o),
+			key:      o.isKey(t, i),
+			multiset: fieldTag.has(multisetTagOption),
+		}
+
+		fp.defaultValue, fp.hasDefault = fieldTag.get(defaultTagOption)
+
+		if fp.kind == valueKind {
+			fp.converter, _ = o.converterFor(root)
+
+			// pointers can hold null, so are never required to be not null
+			fp.notNull = field.Type.Kind() != reflect.Pointer && o.isNotNull(fieldTag)
+		}
+
+		if fp.hasDefault {
+			plan.features |= defaultFeature
+		}
+
+		if fp.notNull {
+			plan.features |= notNullFeature
+		}
+
+		plan.fields = append(plan.fields, fp)
+	}
+
+	return plan
+}
+
+// goFieldName returns the name that the provided field of the struct type (t) is known by in Go,
+// e.g. User.Age (or just Age, where t is an anonymous struct).
+func goFieldName(t reflect.Type, field reflect.StructField) string {
+	if t.Name() == "" {
+		return field.Name
+	}
+
+	return fmt.Sprintf("%s.%s", t.Name(), field.Name)
+}
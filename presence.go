@@ -0,0 +1,107 @@
+package goscanql
+
+import (
+	"reflect"
+)
+
+// presenceType is the type of Presence.
+var presenceType = reflect.TypeOf(Presence{})
+
+// Presence records which of the columns of an entity were null, so that a null column can be told
+// apart from one that held the zero value of its field (without the field being a pointer or a
+// Scanner). goscanql fills in any Presence that is embedded in (or is a field of) an entity that it
+// scans into, e.g.
+//
+//	type User struct {
+//		goscanql.Presence
+//
+//		Email  string `sql:"email"`
+//		Colour Colour `sql:"colour"`
+//	}
+//
+//	user.IsNull("email")
+//	user.IsNull("colour_red")
+//
+// A Presence holds the columns of the fields of its entity, and the fields of its one-to-one
+// children (named relative to the entity). The columns of one-to-many children are held by the
+// Presence of the children themselves. Where an entity is merged from several rows, the Presence
+// of the first row is kept.
+//
+// Where one of these columns is null and its field can't hold null (e.g. a string), the field is
+// left as its zero value, rather than the null causing the scan to fail (unless the field is tagged
+// with the notnull option).
+type Presence struct {
+
+	// nulls holds the names of the columns that were null.
+	nulls map[string]bool
+}
+
+// IsNull returns true if the column with the provided name (relative to the entity, e.g.
+// colour_red) was null. False is returned for columns that weren't in the result set.
+func (p Presence) IsNull(column string) bool {
+	return p.nulls[column]
+}
+
+// fillPresence will fill in the Presence of the fields (and of each of its children) that aren't
+// nil, once the nullFields have been scanned. Only the provided columns (keyed by their
+// columnKey) are recorded, as the nullFields of any other column hold no value.
+func (f *fields) fillPresence(present map[string]bool) {
+	f.crawlFields(func(prefix string, fi *fields) bool {
+		if fi.isNil() {
+			return true
+		}
+
+		if fi.presence != nil {
+			fi.presence.nulls = make(map[string]bool)
+			fi.addNullColumns(prefix, "", present, fi.presence.nulls)
+		}
+
+		return false
+	})
+}
+
+// presenceColumns returns the full names of the columns whose nulls are recorded by a Presence,
+// and whose fields can't hold null.
+func (f *fields) presenceColumns() map[string]bool {
+	columns := make(map[string]bool)
+
+	f.crawlFields(func(prefix string, fi *fields) bool {
+		if fi.presence != nil {
+			fi.addValueColumns(prefix, columns)
+		}
+
+		return false
+	})
+
+	return columns
+}
+
+// addValueColumns will add the full names (with the provided prefix) of the columns of the fields
+// (and of its one-to-one children) whose fields can't hold null to the provided columns.
+func (f *fields) addValueColumns(prefix string, columns map[string]bool) {
+	for name, reference := range f.references {
+		if reflect.TypeOf(reference).Elem().Kind() != reflect.Pointer {
+			columns[buildReferenceName(prefix, name)] = true
+		}
+	}
+
+	for name, child := range f.oneToOnes {
+		child.addValueColumns(buildReferenceName(prefix, name), columns)
+	}
+}
+
+// addNullColumns will add the columns of the fields (and of its one-to-one children) that were null
+// to the provided nulls. Each column is looked up in present by its full name (with the provided
+// prefix), and added to nulls by its name relative to the entity (with the provided relative
+// prefix).
+func (f *fields) addNullColumns(prefix, relative string, present map[string]bool, nulls map[string]bool) {
+	for name, b := range f.nullFields {
+		if b.isNil && present[f.opts.columnKey(buildReferenceName(prefix, name))] {
+			nulls[buildReferenceName(relative, name)] = true
+		}
+	}
+
+	for name, child := range f.oneToOnes {
+		child.addNullColumns(buildReferenceName(prefix, name), buildReferenceName(relative, name), present, nulls)
+	}
+}
@@ -0,0 +1,112 @@
+package goscanql
+
+import (
+	"database/sql/driver"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+type presenceTestColour struct {
+	Red  int `sql:"red"`
+	Blue int `sql:"blue"`
+}
+
+type presenceTestPet struct {
+	Presence
+
+	Name string `sql:"name"`
+	Age  int    `sql:"age"`
+}
+
+type presenceTestUser struct {
+	Presence
+
+	ID     int                `sql:"id"`
+	Email  string             `sql:"email"`
+	Colour presenceTestColour `sql:"colour"`
+	Pets   []*presenceTestPet `sql:"pets"`
+}
+
+func TestPresence(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		panic(err)
+	}
+
+	columns := []string{"id", "email", "colour_red", "colour_blue", "pets_name", "pets_age", "unmapped"}
+	inputRows := sqlmock.NewRows(columns)
+
+	for _, row := range [][]driver.Value{
+		{1, "", 0, nil, "Bandit", nil, nil},
+		{1, "", 0, nil, "Babou", 0, nil},
+		{2, nil, nil, nil, nil, nil, nil},
+	} {
+		inputRows.AddRow(row...)
+	}
+
+	mock.ExpectQuery("SELECT").WillReturnRows(inputRows)
+
+	rows, err := db.Query("SELECT")
+	if err != nil {
+		panic(err)
+	}
+
+	// Act
+	result, err := RowsToStructs[*presenceTestUser](rows, WithCaseInsensitiveColumns())
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Len(t, result, 2)
+
+	assert.False(t, result[0].IsNull("id"))
+	assert.False(t, result[0].IsNull("email"))
+	assert.False(t, result[0].IsNull("colour_red"))
+	assert.True(t, result[0].IsNull("colour_blue"))
+	assert.False(t, result[0].IsNull("pets_name"))
+	assert.False(t, result[0].IsNull("unmapped"))
+	assert.False(t, result[0].IsNull("missing"))
+
+	assert.Len(t, result[0].Pets, 2)
+	assert.False(t, result[0].Pets[0].IsNull("name"))
+	assert.True(t, result[0].Pets[0].IsNull("age"))
+	assert.False(t, result[0].Pets[1].IsNull("age"))
+	assert.Equal(t, 0, result[0].Pets[0].Age)
+
+	assert.True(t, result[1].IsNull("email"))
+	assert.Equal(t, "", result[1].Email)
+	assert.True(t, result[1].IsNull("colour_red"))
+	assert.True(t, result[1].IsNull("colour_blue"))
+	assert.Equal(t, presenceTestColour{}, result[1].Colour)
+	assert.Nil(t, result[1].Pets)
+}
+
+func TestPresenceWithNotNull(t *testing.T) {
+	type user struct {
+		Presence
+
+		ID    int    `sql:"id"`
+		Email string `sql:"email,notnull"`
+	}
+
+	// Arrange
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		panic(err)
+	}
+
+	mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"id", "email"}).AddRow(1, nil))
+
+	rows, err := db.Query("SELECT")
+	if err != nil {
+		panic(err)
+	}
+
+	// Act
+	_, err = RowsToStructs[user](rows)
+
+	// Assert
+	assert.Equal(t, &ScanError{Column: "email", Field: "user.Email", Err: ErrUnexpectedNull}, err)
+}
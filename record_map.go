@@ -1,6 +1,7 @@
 package goscanql
 
 import (
+	"fmt"
 	"reflect"
 )
 
@@ -38,86 +39,299 @@ type recordMap[T any] struct {
 	// of recordMap. This is used for entity matching during a merge to ensure that new data is added in
 	// the right place rather than adding duplicate values.
 	hashTable recordList
+
+	// ctx holds the state that is shared between each merge into the recordMap.
+	ctx *mergeContext
+}
+
+// mergeContext holds any state that must be shared across all of the merges of a single scan,
+// rather than belonging to a particular recordList. A nil *mergeContext is valid and represents
+// goscanql's default behaviour.
+type mergeContext struct {
+
+	// identityMap is the identityMap used to share instances of entities across the result (nil
+	// if disabled).
+	identityMap identityMap
+
+	// row is the ordinal of the row currently being merged.
+	row int
+}
+
+// newMergeContext creates a new mergeContext based on the provided options (o).
+func newMergeContext(o *options) *mergeContext {
+	ctx := &mergeContext{}
+
+	if o != nil && o.identityMap {
+		ctx.identityMap = identityMap{}
+	}
+
+	return ctx
+}
+
+// identities returns the identityMap of the mergeContext, or nil if there isn't one.
+func (ctx *mergeContext) identities() identityMap {
+	if ctx == nil {
+		return nil
+	}
+
+	return ctx.identityMap
+}
+
+// recordKey returns the key that the provided fields (with the provided hash) should be
+// recorded against in a recordList. This is the hash itself, unless the fields is a multiset
+// child, in which case the row ordinal is included so that duplicates aren't merged.
+func (ctx *mergeContext) recordKey(entry *fields, hash string) string {
+	if !entry.multiset || ctx == nil {
+		return hash
+	}
+
+	return fmt.Sprintf("%s#%d", hash, ctx.row)
+}
+
+// identityMap maintains a single instance of each entity that is referenced by a pointer, so
+// that the same entity can be shared wherever it appears in a result. Entities are keyed by their
+// type, and then by their hash.
+type identityMap map[reflect.Type]map[string]identity
+
+// identity represents a single shared entity of an identityMap.
+type identity struct {
+
+	// value is the pointer to the shared entity.
+	value reflect.Value
+
+	// otmChildren is the list of child one-to-many relationships of the shared entity, which is
+	// shared by every record that refers to the entity.
+	otmChildren map[string]recordList
+}
+
+// lookup will return the shared entity for the provided value (v) and hash, if there is one.
+func (im identityMap) lookup(v reflect.Value, hash string) (identity, bool) {
+	if im == nil || !isSharable(v) {
+		return identity{}, false
+	}
+
+	shared, ok := im[v.Type()][hash]
+	return shared, ok
+}
+
+// register will add the provided value (v) to the identityMap as the shared instance of the
+// entity with the provided hash.
+func (im identityMap) register(v reflect.Value, hash string, otmChildren map[string]recordList) {
+	if im == nil || !isSharable(v) {
+		return
+	}
+
+	if _, ok := im[v.Type()]; !ok {
+		im[v.Type()] = map[string]identity{}
+	}
+
+	im[v.Type()][hash] = identity{
+		value:       v,
+		otmChildren: otmChildren,
+	}
 }
 
-// insert will add the provided value of rv to the provided slice as a new value.
-func (rl recordList) insert(entry *fields, rv *reflect.Value, slice interface{}) {
-	// only perform append if the provided value isn't nil (suggesting that the insert is at
-	// the point in the fields where it needs to be appended). Children after this point don't
-	// need to be appended because they already exist in obj.
-	if rv != nil {
-		srv := reflect.ValueOf(slice).Elem()
-		srv.Set(reflect.Append(srv, *rv))
+// isSharable returns true if the provided value (v) is a (non-nil) pointer to a struct, and
+// can therefore be shared by an identityMap.
+func isSharable(v reflect.Value) bool {
+	return v.Kind() == reflect.Pointer && !v.IsNil() && v.Elem().Kind() == reflect.Struct
+}
+
+// insert will add the provided value of rv to the provided slice as a new value, before
+// recursively merging each of the entity's one-to-many children into their (now empty) slices
+// so that they are recorded too.
+func (rl recordList) insert(entry *fields, rv *reflect.Value, slice interface{}, ctx *mergeContext) error {
+	hash := entry.getHash()
+	key := ctx.recordKey(entry, hash)
+
+	// if an instance of this entity already exists elsewhere in the result, then that instance
+	// is shared rather than a new one being added
+	if shared, ok := ctx.identities().lookup(*rv, hash); ok {
+		appendToSlice(slice, shared.value)
+		rl[key] = record{
+			index:       len(rl),
+			otmChildren: shared.otmChildren,
+		}
+
+		return mergeInto(entry, getRootValue(shared.value), shared.otmChildren, ctx)
 	}
 
+	srv := appendToSlice(slice, *rv)
+	entity := getRootValue(srv.Index(srv.Len() - 1))
+
 	r := record{
 		index:       len(rl),
 		otmChildren: map[string]recordList{},
 	}
 
 	for fieldName, child := range entry.oneToManys {
+		childSlice := getRootValue(*fieldByTag(fieldName, entity, entry.opts))
+
+		// take the elements provided by the entity and empty its slice, so that they can be
+		// merged back in (and recorded) one by one
+		elements := reflect.ValueOf(childSlice.Interface())
+		childSlice.Set(reflect.Zero(childSlice.Type()))
+
 		rlChild := recordList{}
-		rlChild.insert(child, nil, nil)
 		r.otmChildren[fieldName] = rlChild
+
+		for i := 0; i < elements.Len(); i++ {
+			element := elements.Index(i)
+
+			err := rlChild.merge(child, &element, childSlice.Addr().Interface(), ctx)
+			if err != nil {
+				return err
+			}
+		}
 	}
 
-	rl[entry.getHash()] = r
+	rl[key] = r
+
+	ctx.identities().register(*rv, hash, r.otmChildren)
+	shareOneToOnes(entry, entity, ctx)
+
+	return nil
 }
 
 // merge will recursively search the provided fields against the stored records to determine
 // how the value represented by fields should be combined into the existing entries. Where a
 // one-to-many relationship is found where no child matches the hash of the fields, this will
 // be added as a new value in the one-to-many slice.
-func (rl recordList) merge(entry *fields, rv *reflect.Value, slice interface{}) {
+func (rl recordList) merge(entry *fields, rv *reflect.Value, slice interface{}, ctx *mergeContext) error {
 	if entry.isNil() {
-		return
+		return nil
 	}
 
-	f, ok := rl[entry.getHash()]
+	f, ok := rl[ctx.recordKey(entry, entry.getHash())]
 	if !ok {
-		rl.insert(entry, rv, slice)
-		return
+		return rl.insert(entry, rv, slice, ctx)
 	}
 
 	match := getRootValue(reflect.ValueOf(slice).Elem().Index(f.index))
+	return mergeInto(entry, match, f.otmChildren, ctx)
+}
 
+// mergeInto will merge each of the one-to-many children of the provided fields into the
+// matching child slices of an existing entity (match), using the existing entity's records
+// (otmChildren). Once merged, AfterMerge is called on the existing entity (if implemented).
+func mergeInto(entry *fields, match reflect.Value, otmChildren map[string]recordList, ctx *mergeContext) error {
 	for fieldName, child := range entry.oneToManys {
-		childSlice := getRootValue(*fieldByTag(fieldName, match))
+		childSlice := getRootValue(*fieldByTag(fieldName, match, entry.opts))
 		rvChild := reflect.ValueOf(child.obj).Elem()
 
-		f.otmChildren[fieldName].merge(child, &rvChild, childSlice.Addr().Interface())
+		err := otmChildren[fieldName].merge(child, &rvChild, childSlice.Addr().Interface(), ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return callAfterMerge(match)
+}
+
+// shareOneToOnes will replace any pointer one-to-one children of the provided entity with
+// the instance already held in the identity map (if there is one), or otherwise register
+// them in the identity map.
+func shareOneToOnes(entry *fields, entity reflect.Value, ctx *mergeContext) {
+	if ctx.identities() == nil {
+		return
+	}
+
+	for fieldName, child := range entry.oneToOnes {
+		if child.isNil() {
+			continue
+		}
+
+		field := fieldByTag(fieldName, entity, entry.opts)
+		if field == nil {
+			continue
+		}
+
+		// find the pointer that points directly at the child struct (e.g. **Struct would
+		// share the *Struct)
+		ptr := *field
+		for ptr.Kind() == reflect.Pointer && ptr.Elem().Kind() == reflect.Pointer {
+			ptr = ptr.Elem()
+		}
+
+		hash := child.getHash()
+
+		if shared, ok := ctx.identities().lookup(ptr, hash); ok {
+			ptr.Set(shared.value)
+			continue
+		}
+
+		ctx.identities().register(ptr, hash, nil)
+		shareOneToOnes(child, getRootValue(ptr), ctx)
 	}
 }
 
+// appendToSlice will append the provided value (v) to the slice being pointed to (slice), and
+// return the resulting slice.
+func appendToSlice(slice interface{}, v reflect.Value) reflect.Value {
+	srv := reflect.ValueOf(slice).Elem()
+	srv.Set(reflect.Append(srv, v))
+
+	return srv
+}
+
 // merge will apply the provided fields to the existing entities maintained by recordMap, using
 // fields hash values to determine where the data already exists, or where it should be added
 // as new.
-func (rm *recordMap[T]) merge(entry *fields) {
+func (rm *recordMap[T]) merge(entry *fields) error {
+	rm.ctx.row++
+
 	rv := reflect.ValueOf(entry.obj).Elem()
-	rm.hashTable.merge(entry, &rv, &rm.entries)
+	return rm.hashTable.merge(entry, &rv, &rm.entries, rm.ctx)
+}
+
+// sort will sort the entries of the recordMap by the keys provided in the options (if any), and
+// then sort the children of every one-to-many relationship that is tagged with the orderby
+// option. This must only be called once all of the rows have been merged, as the records of the
+// recordMap refer to entities by their position.
+func (rm *recordMap[T]) sort(o *options) error {
+	entries := reflect.ValueOf(rm.entries)
+
+	if o != nil && len(o.sortBy) > 0 {
+		keys, err := parseSortKeys(o.sortBy...)
+		if err != nil {
+			return err
+		}
+
+		err = sortSlice(entries, keys, o)
+		if err != nil {
+			return err
+		}
+	}
+
+	return sortEntities(entries, o)
+}
+
+// finalize will call Finalize and Validate on every entity maintained by the recordMap (where
+// implemented). This must only be called once all of the rows have been merged.
+func (rm *recordMap[T]) finalize(o *options) error {
+	return finalizeEntities(reflect.ValueOf(rm.entries), o, map[uintptr]bool{})
 }
 
 // newRecordMap is the constructor for record map, and will return an instantiated recordMap
-// based on the provided type T.
-func newRecordMap[T any]() *recordMap[T] {
+// based on the provided type T and options (o).
+func newRecordMap[T any](o *options) *recordMap[T] {
 	return &recordMap[T]{
 		entries:   make([]T, 0),
 		hashTable: recordList{},
+		ctx:       newMergeContext(o),
 	}
 }
 
 // fieldByTag will look up a field of the provided value (v) by the field's tag value (where
-// the field is tagged with sql). If no field matches the provided tag, then nil is
-// returned.
-func fieldByTag(tag string, v reflect.Value) *reflect.Value {
-	tv := v.Type()
-
-	for i := 0; i < v.NumField(); i++ {
-		if tv.Field(i).Tag.Get(scanqlTag) != tag {
+// the field is tagged with sql, or named by the NameMapper of o). If no field matches the
+// provided tag, then nil is returned.
+func fieldByTag(tag string, v reflect.Value, o *options) *reflect.Value {
+	for _, fp := range o.planOf(v.Type()).fields {
+		if fp.name != tag {
 			continue
 		}
 
-		f := v.Field(i)
+		f := v.Field(fp.index)
 		return &f
 	}
 
@@ -70,7 +70,7 @@ func Test_fieldByTag(t *testing.T) {
 			inputValue := reflect.ValueOf(testInputs[test.inputValueKey])
 
 			// Act
-			result := fieldByTag(test.inputTag, inputValue)
+			result := fieldByTag(test.inputTag, inputValue, nil)
 
 			// Assert
 
@@ -224,9 +224,10 @@ func TestRecordList_insert(t *testing.T) {
 	}
 
 	// Act
-	inputRecordList.insert(inputFields, referenceField(reflect.ValueOf(inputFields.obj).Elem()), &inputSlice)
+	err := inputRecordList.insert(inputFields, referenceField(reflect.ValueOf(inputFields.obj).Elem()), &inputSlice, nil)
 
 	// Assert
+	assert.Nil(t, err)
 	assert.Equal(t, expectedRecordList, inputRecordList)
 	assert.Equal(t, expectedSlice, inputSlice)
 }
@@ -389,9 +390,10 @@ func TestRecordList_merge(t *testing.T) {
 			inputFields := generateTestFields()
 
 			// Act
-			test.inputRecordList.merge(inputFields, referenceField(reflect.ValueOf(inputFields.obj).Elem()), &test.inputSlice)
+			err := test.inputRecordList.merge(inputFields, referenceField(reflect.ValueOf(inputFields.obj).Elem()), &test.inputSlice, nil)
 
 			// Assert
+			assert.Nil(t, err)
 			assert.Equal(t, test.expectedRecordList, test.inputRecordList)
 			assert.Equal(t, test.expectedSlice, test.inputSlice)
 		})
@@ -1,6 +1,8 @@
 package goscanql
 
 import (
+	"database/sql/driver"
+	"fmt"
 	"strings"
 	"testing"
 	"time"
@@ -362,3 +364,560 @@ func Test_RecordListNilMapAssignment(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, expected, result)
 }
+
+func Test_RowsToStructsWithNameMapper(t *testing.T) {
+	type pet struct {
+		Name   string
+		Animal string `sql:"kind"`
+	}
+
+	type account struct {
+		ID          int
+		DateOfBirth time.Time
+		Ignored     string `sql:"-"`
+		unexported  string
+		Pets        []pet
+	}
+
+	tests := []struct {
+		name     string
+		columns  []string
+		opts     []Option
+		expected []account
+	}{
+		{
+			name:    "GivenSnakeCaseMapper_ThenUntaggedFieldsAreMapped",
+			columns: []string{"id", "date_of_birth", "ignored", "unexported", "pets_name", "pets_kind"},
+			opts:    []Option{WithNameMapper(SnakeCase)},
+			expected: []account{
+				{
+					ID:          1,
+					DateOfBirth: time.Date(1978, 12, 30, 0, 0, 0, 0, time.UTC),
+					Pets: []pet{
+						{Name: "Babou", Animal: "ocelot"},
+						{Name: "Gustavo", Animal: "horse"},
+					},
+				},
+			},
+		},
+		{
+			name:    "GivenCaseInsensitiveColumns_ThenColumnsAreMatchedRegardlessOfCase",
+			columns: []string{"ID", "Date_Of_Birth", "Ignored", "Unexported", "PETS_NAME", "Pets_Kind"},
+			opts:    []Option{WithNameMapper(SnakeCase), WithCaseInsensitiveColumns()},
+			expected: []account{
+				{
+					ID:          1,
+					DateOfBirth: time.Date(1978, 12, 30, 0, 0, 0, 0, time.UTC),
+					Pets: []pet{
+						{Name: "Babou", Animal: "ocelot"},
+						{Name: "Gustavo", Animal: "horse"},
+					},
+				},
+			},
+		},
+		{
+			name:    "GivenNoMapper_ThenUntaggedFieldsAreIgnored",
+			columns: []string{"id", "date_of_birth", "ignored", "unexported", "pets_name", "pets_kind"},
+			opts:    nil,
+			// the root struct has no mapped fields, so every row is treated as nil
+			expected: []account{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Arrange
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				panic(err)
+			}
+
+			inputRows := sqlmock.NewRows(test.columns)
+			inputRows.AddRow(1, time.Date(1978, 12, 30, 0, 0, 0, 0, time.UTC), "ignored", "unexported", "Babou", "ocelot")
+			inputRows.AddRow(1, time.Date(1978, 12, 30, 0, 0, 0, 0, time.UTC), "ignored", "unexported", "Gustavo", "horse")
+
+			mock.ExpectQuery("SELECT").WillReturnRows(inputRows)
+
+			rows, err := db.Query("SELECT")
+			if err != nil {
+				panic(err)
+			}
+
+			// Act
+			result, err := RowsToStructs[account](rows, test.opts...)
+
+			// Assert
+			assert.Nil(t, err)
+			assert.Equal(t, test.expected, result)
+		})
+	}
+}
+
+func Test_RowsToStructsWithIdentityMap(t *testing.T) {
+	type colour struct {
+		Name string `sql:"name"`
+	}
+
+	type synonym struct {
+		Word string `sql:"word"`
+	}
+
+	type tag struct {
+		Label    string    `sql:"label"`
+		Colour   *colour   `sql:"colour"`
+		Synonyms []synonym `sql:"synonym"`
+	}
+
+	type product struct {
+		ID     int     `sql:"id"`
+		Colour *colour `sql:"colour"`
+		Tags   []*tag  `sql:"tag"`
+	}
+
+	// Arrange
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		panic(err)
+	}
+
+	columns := []string{"id", "colour_name", "tag_label", "tag_colour_name", "tag_synonym_word"}
+	inputRows := sqlmock.NewRows(columns)
+
+	inputRows.AddRow(1, "red", "sale", "red", "discount")
+	inputRows.AddRow(1, "red", "new", nil, nil)
+	inputRows.AddRow(2, "red", "sale", "red", "offer")
+	inputRows.AddRow(2, "blue", "sale", "red", "discount")
+	inputRows.AddRow(3, nil, "new", nil, nil)
+
+	mock.ExpectQuery("SELECT").WillReturnRows(inputRows)
+
+	rows, err := db.Query("SELECT")
+	if err != nil {
+		panic(err)
+	}
+
+	// Act
+	result, err := RowsToStructs[product](rows, WithIdentityMap())
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Len(t, result, 4)
+
+	red := &colour{Name: "red"}
+	sale := &tag{Label: "sale", Colour: red, Synonyms: []synonym{{Word: "discount"}, {Word: "offer"}}}
+	brandNew := &tag{Label: "new"}
+
+	assert.Equal(t, []product{
+		{ID: 1, Colour: red, Tags: []*tag{sale, brandNew}},
+		{ID: 2, Colour: red, Tags: []*tag{sale}},
+		{ID: 2, Colour: &colour{Name: "blue"}, Tags: []*tag{sale}},
+		{ID: 3, Colour: nil, Tags: []*tag{brandNew}},
+	}, result)
+
+	// assert that the pointers to equal entities are shared across the result
+	assert.Same(t, result[0].Colour, result[1].Colour)
+	assert.Same(t, result[0].Colour, result[0].Tags[0].Colour)
+	assert.Same(t, result[0].Tags[0], result[1].Tags[0])
+	assert.Same(t, result[0].Tags[0], result[2].Tags[0])
+	assert.Same(t, result[0].Tags[1], result[3].Tags[0])
+}
+
+func Test_RowsToStructsWithMultiset(t *testing.T) {
+	type lineItem struct {
+		Product  string `sql:"product"`
+		Quantity int    `sql:"quantity"`
+	}
+
+	type order struct {
+		ID      int        `sql:"id"`
+		Items   []lineItem `sql:"item,multiset"`
+		Aliases []string   `sql:"alias,multiset"`
+		Notes   []string   `sql:"note"`
+	}
+
+	// Arrange
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		panic(err)
+	}
+
+	columns := []string{"id", "item_product", "item_quantity", "alias", "note"}
+	inputRows := sqlmock.NewRows(columns)
+
+	inputRows.AddRow(1, "pen", 1, "a", "fragile")
+	inputRows.AddRow(1, "ink", 2, "b", "fragile")
+	inputRows.AddRow(1, "pen", 1, "a", "fragile")
+	inputRows.AddRow(2, nil, nil, nil, nil)
+	inputRows.AddRow(3, "pad", 1, "c", "gift")
+	inputRows.AddRow(3, "pad", 1, "c", "gift")
+
+	mock.ExpectQuery("SELECT").WillReturnRows(inputRows)
+
+	rows, err := db.Query("SELECT")
+	if err != nil {
+		panic(err)
+	}
+
+	expected := []order{
+		{
+			ID:      1,
+			Items:   []lineItem{{Product: "pen", Quantity: 1}, {Product: "ink", Quantity: 2}, {Product: "pen", Quantity: 1}},
+			Aliases: []string{"a", "b", "a"},
+			Notes:   []string{"fragile"},
+		},
+		{
+			ID: 2,
+		},
+		{
+			ID:      3,
+			Items:   []lineItem{{Product: "pad", Quantity: 1}, {Product: "pad", Quantity: 1}},
+			Aliases: []string{"c", "c"},
+			Notes:   []string{"gift"},
+		},
+	}
+
+	// Act
+	result, err := RowsToStructs[order](rows)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, expected, result)
+}
+
+func Test_RowsToStructsWithMultisetGrandchildren(t *testing.T) {
+	type lineItem struct {
+		Product string   `sql:"product"`
+		Options []string `sql:"option"`
+	}
+
+	type order struct {
+		ID    int        `sql:"id"`
+		Items []lineItem `sql:"item,multiset"`
+	}
+
+	// Arrange
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		panic(err)
+	}
+
+	columns := []string{"id", "item_product", "item_option"}
+	inputRows := sqlmock.NewRows(columns)
+
+	inputRows.AddRow(1, "pen", "blue")
+	inputRows.AddRow(1, "pen", "gift wrap")
+
+	mock.ExpectQuery("SELECT").WillReturnRows(inputRows)
+
+	rows, err := db.Query("SELECT")
+	if err != nil {
+		panic(err)
+	}
+
+	// each row produces its own multiset child, so the options of the pen aren't aggregated
+	expected := []order{
+		{
+			ID: 1,
+			Items: []lineItem{
+				{Product: "pen", Options: []string{"blue"}},
+				{Product: "pen", Options: []string{"gift wrap"}},
+			},
+		},
+	}
+
+	// Act
+	result, err := RowsToStructs[order](rows)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, expected, result)
+}
+
+func Test_RowsToStructsWithOrdering(t *testing.T) {
+	type colour struct {
+		Name string `sql:"name"`
+	}
+
+	type pet struct {
+		Name   string  `sql:"name"`
+		Colour *colour `sql:"colour"`
+	}
+
+	type account struct {
+		ID      int      `sql:"id"`
+		Name    string   `sql:"name"`
+		Pets    []pet    `sql:"pets,orderby=name desc"`
+		ByHue   []pet    `sql:"hues,orderby=colour_name;name"`
+		Aliases []string `sql:"alias,orderby"`
+	}
+
+	columns := []string{"id", "name", "pets_name", "pets_colour_name", "hues_name", "hues_colour_name", "alias"}
+
+	addRows := func(inputRows *sqlmock.Rows) {
+		inputRows.AddRow(1, "Archer", "Babou", "tan", "Babou", "tan", "Duchess")
+		inputRows.AddRow(1, "Archer", "Gustavo", "brown", "Gustavo", "brown", "Bobo")
+		inputRows.AddRow(1, "Archer", "Bandit", nil, "Bandit", nil, "Duchess")
+		inputRows.AddRow(2, "Cheryl", nil, nil, nil, nil, nil)
+		inputRows.AddRow(3, "Barry", nil, nil, nil, nil, nil)
+	}
+
+	archer := account{
+		ID:      1,
+		Name:    "Archer",
+		Pets:    []pet{{Name: "Gustavo", Colour: &colour{Name: "brown"}}, {Name: "Bandit"}, {Name: "Babou", Colour: &colour{Name: "tan"}}},
+		ByHue:   []pet{{Name: "Bandit"}, {Name: "Gustavo", Colour: &colour{Name: "brown"}}, {Name: "Babou", Colour: &colour{Name: "tan"}}},
+		Aliases: []string{"Bobo", "Duchess"},
+	}
+
+	tests := []struct {
+		name        string
+		opts        []Option
+		expected    []account
+		expectedErr error
+	}{
+		{
+			name:     "GivenNoSortBy_ThenRootsInFirstSeenOrder",
+			expected: []account{archer, {ID: 2, Name: "Cheryl"}, {ID: 3, Name: "Barry"}},
+		},
+		{
+			name:     "GivenSortBy_ThenRootsSorted",
+			opts:     []Option{WithSortBy("name")},
+			expected: []account{archer, {ID: 3, Name: "Barry"}, {ID: 2, Name: "Cheryl"}},
+		},
+		{
+			name:     "GivenSortByDescending_ThenRootsSortedDescending",
+			opts:     []Option{WithSortBy("id desc")},
+			expected: []account{{ID: 3, Name: "Barry"}, {ID: 2, Name: "Cheryl"}, archer},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Arrange
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				panic(err)
+			}
+
+			inputRows := sqlmock.NewRows(columns)
+			addRows(inputRows)
+
+			mock.ExpectQuery("SELECT").WillReturnRows(inputRows)
+
+			rows, err := db.Query("SELECT")
+			if err != nil {
+				panic(err)
+			}
+
+			// Act
+			result, err := RowsToStructs[account](rows, test.opts...)
+
+			// Assert
+			assert.Equal(t, test.expectedErr, err)
+			assert.Equal(t, test.expected, result)
+		})
+	}
+}
+
+func Test_RowsToStructsWithNotNull(t *testing.T) {
+	type toy struct {
+		Name string `sql:"name"`
+	}
+
+	type pet struct {
+		Name  string  `sql:"name"`
+		Age   int     `sql:"age,notnull"`
+		Owner *string `sql:"owner,notnull"`
+		Toys  []toy   `sql:"toy"`
+	}
+
+	type person struct {
+		ID       int        `sql:"id"`
+		Age      int        `sql:"age"`
+		Nickname NullString `sql:"nickname"`
+		Pets     []pet      `sql:"pet"`
+	}
+
+	columns := []string{"id", "age", "nickname", "pet_name", "pet_age", "pet_owner", "pet_toy_name"}
+
+	tests := []struct {
+		name        string
+		opts        []Option
+		rows        [][]driver.Value
+		expected    []person
+		expectedErr error
+	}{
+		{
+			name: "GivenNullIntoNotNullField_ThenScanErrorReturned",
+			rows: [][]driver.Value{
+				{1, 30, "Archie", "Bandit", nil, nil, nil},
+			},
+			expectedErr: &ScanError{Column: "pet_age", Field: "pet.Age", Err: ErrUnexpectedNull},
+		},
+		{
+			name: "GivenNilChild_ThenNoScanError",
+			rows: [][]driver.Value{
+				{1, 30, nil, nil, nil, nil, nil},
+				{2, 40, nil, "Bandit", 3, nil, nil},
+			},
+			expected: []person{
+				{ID: 1, Age: 30},
+				{ID: 2, Age: 40, Pets: []pet{{Name: "Bandit", Age: 3}}},
+			},
+		},
+		{
+			name: "GivenNullWithNotNullOption_ThenScanErrorReturned",
+			opts: []Option{WithNotNull()},
+			rows: [][]driver.Value{
+				{1, nil, nil, nil, nil, nil, nil},
+			},
+			expectedErr: &ScanError{Column: "age", Field: "person.Age", Err: ErrUnexpectedNull},
+		},
+		{
+			name: "GivenNullScannerWithNotNullOption_ThenNoScanError",
+			opts: []Option{WithNotNull()},
+			rows: [][]driver.Value{
+				{1, 30, nil, "Bandit", 3, nil, "ball"},
+			},
+			expected: []person{
+				{ID: 1, Age: 30, Pets: []pet{{Name: "Bandit", Age: 3, Toys: []toy{{Name: "ball"}}}}},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Arrange
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				panic(err)
+			}
+
+			inputRows := sqlmock.NewRows(columns)
+			for _, row := range test.rows {
+				inputRows.AddRow(row...)
+			}
+
+			mock.ExpectQuery("SELECT").WillReturnRows(inputRows)
+
+			rows, err := db.Query("SELECT")
+			if err != nil {
+				panic(err)
+			}
+
+			// Act
+			result, err := RowsToStructs[person](rows, test.opts...)
+
+			// Assert
+			assert.Equal(t, test.expectedErr, err)
+			assert.Equal(t, test.expected, result)
+
+			if test.expectedErr != nil {
+				assert.ErrorIs(t, err, ErrUnexpectedNull)
+			}
+		})
+	}
+}
+
+func Test_RowsToStructsWithNotNullAndMissingColumn(t *testing.T) {
+	type person struct {
+		ID  int `sql:"id"`
+		Age int `sql:"age,notnull"`
+	}
+
+	// Arrange
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		panic(err)
+	}
+
+	mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	rows, err := db.Query("SELECT")
+	if err != nil {
+		panic(err)
+	}
+
+	// Act
+	result, err := RowsToStructs[person](rows)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []person{{ID: 1}}, result)
+}
+
+func Test_RowsToStructsWithNotNullInAnonymousStruct(t *testing.T) {
+	type person struct {
+		ID      int `sql:"id"`
+		Address struct {
+			Street string `sql:"street,notnull"`
+			City   string `sql:"city"`
+		} `sql:"address"`
+	}
+
+	// Arrange
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		panic(err)
+	}
+
+	mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"id", "address_street", "address_city"}).AddRow(1, nil, "London"))
+
+	rows, err := db.Query("SELECT")
+	if err != nil {
+		panic(err)
+	}
+
+	// Act
+	_, err = RowsToStructs[person](rows)
+
+	// Assert
+	assert.Equal(t, &ScanError{Column: "address_street", Field: "Street", Err: ErrUnexpectedNull}, err)
+}
+
+func BenchmarkRowsToStructs(b *testing.B) {
+	type pet struct {
+		Name   string `sql:"name"`
+		Animal string `sql:"animal"`
+	}
+
+	type person struct {
+		ID    int    `sql:"id"`
+		Name  string `sql:"name"`
+		Email string `sql:"email"`
+		Age   int    `sql:"age"`
+		Pets  []pet  `sql:"pets"`
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		panic(err)
+	}
+
+	values := make([][]driver.Value, 2000)
+	for i := range values {
+		values[i] = []driver.Value{i / 4, fmt.Sprintf("person %d", i/4), "person@example.com", 30, fmt.Sprintf("pet %d", i), "cat"}
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		inputRows := sqlmock.NewRows([]string{"id", "name", "email", "age", "pets_name", "pets_animal"})
+		for _, row := range values {
+			inputRows.AddRow(row...)
+		}
+
+		mock.ExpectQuery("SELECT").WillReturnRows(inputRows)
+
+		rows, err := db.Query("SELECT")
+		if err != nil {
+			panic(err)
+		}
+
+		_, err = RowsToStructs[person](rows)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package goscanql
 
 import (
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
 	"reflect"
 	"strconv"
@@ -58,6 +59,14 @@ func (bs *ByteSlice) ID() []byte {
 	return *bs
 }
 
+func (bs ByteSlice) Value() (driver.Value, error) {
+	if bs == nil {
+		return nil, nil
+	}
+
+	return []byte(bs), nil
+}
+
 // NullString represents a string that can be null. If null, then the attribute
 // Valid will be set to false, otherwise the value stored in String represents the
 // string value. This type implements the goscanql Scanner interface and can be
@@ -90,6 +99,14 @@ func (ns *NullString) ID() []byte {
 	return []byte(ns.String)
 }
 
+func (ns NullString) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+
+	return ns.String, nil
+}
+
 // NullInt64 represents a string that can be null. If null, then the attribute
 // Valid will be set to false, otherwise the value stored in Int64 represents the
 // int64 value. This type implements the goscanql Scanner interface and can be
@@ -122,6 +139,14 @@ func (ni *NullInt64) ID() []byte {
 	return []byte(strconv.FormatInt(ni.Int64, 10))
 }
 
+func (ni NullInt64) Value() (driver.Value, error) {
+	if !ni.Valid {
+		return nil, nil
+	}
+
+	return ni.Int64, nil
+}
+
 // NullInt32 represents a string that can be null. If null, then the attribute
 // Valid will be set to false, otherwise the value stored in Int32 represents the
 // int32 value. This type implements the goscanql Scanner interface and can be
@@ -154,6 +179,14 @@ func (ni *NullInt32) ID() []byte {
 	return []byte(strconv.FormatInt(int64(ni.Int32), 10))
 }
 
+func (ni NullInt32) Value() (driver.Value, error) {
+	if !ni.Valid {
+		return nil, nil
+	}
+
+	return int64(ni.Int32), nil
+}
+
 // NullInt16 represents a string that can be null. If null, then the attribute
 // Valid will be set to false, otherwise the value stored in Int16 represents the
 // int16 value. This type implements the goscanql Scanner interface and can be
@@ -186,6 +219,14 @@ func (ni *NullInt16) ID() []byte {
 	return []byte(strconv.FormatInt(int64(ni.Int16), 10))
 }
 
+func (ni NullInt16) Value() (driver.Value, error) {
+	if !ni.Valid {
+		return nil, nil
+	}
+
+	return int64(ni.Int16), nil
+}
+
 // NullByte represents a string that can be null. If null, then the attribute
 // Valid will be set to false, otherwise the value stored in Byte represents the
 // byte value. This type implements the goscanql Scanner interface and can be
@@ -218,6 +259,14 @@ func (ni *NullByte) ID() []byte {
 	return []byte{ni.Byte}
 }
 
+func (ni NullByte) Value() (driver.Value, error) {
+	if !ni.Valid {
+		return nil, nil
+	}
+
+	return int64(ni.Byte), nil
+}
+
 // NullFloat64 represents a string that can be null. If null, then the attribute
 // Valid will be set to false, otherwise the value stored in Float64 represents the
 // float64 value. This type implements the goscanql Scanner interface and can be
@@ -250,6 +299,14 @@ func (ni *NullFloat64) ID() []byte {
 	return []byte(strconv.FormatFloat(ni.Float64, 'f', -1, 64))
 }
 
+func (ni NullFloat64) Value() (driver.Value, error) {
+	if !ni.Valid {
+		return nil, nil
+	}
+
+	return ni.Float64, nil
+}
+
 // NullBool represents a string that can be null. If null, then the attribute
 // Valid will be set to false, otherwise the value stored in Bool represents the
 // bool value. This type implements the goscanql Scanner interface and can be
@@ -282,6 +339,14 @@ func (ni *NullBool) ID() []byte {
 	return []byte(strconv.FormatBool(ni.Bool))
 }
 
+func (ni NullBool) Value() (driver.Value, error) {
+	if !ni.Valid {
+		return nil, nil
+	}
+
+	return ni.Bool, nil
+}
+
 // NullTime represents a string that can be null. If null, then the attribute
 // Valid will be set to false, otherwise the value stored in Time represents the
 // time value. This type implements the goscanql Scanner interface and can be
@@ -311,5 +376,13 @@ func (ni *NullTime) ID() []byte {
 		return nil
 	}
 
-	return []byte(ni.Time.Format(time.RFC3339Nano))
+	return []byte(timeID(ni.Time))
+}
+
+func (ni NullTime) Value() (driver.Value, error) {
+	if !ni.Valid {
+		return nil, nil
+	}
+
+	return ni.Time, nil
 }
@@ -1,6 +1,7 @@
 package goscanql
 
 import (
+	"database/sql/driver"
 	"fmt"
 	"testing"
 	"time"
@@ -835,3 +836,83 @@ func TestNullTime_Scan(t *testing.T) {
 		})
 	}
 }
+
+func TestScanners_Value(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    driver.Valuer
+		expected driver.Value
+	}{
+		{
+			name:     "ByteSlice",
+			input:    ByteSlice("1234"),
+			expected: []byte("1234"),
+		},
+		{
+			name:     "Nil ByteSlice",
+			input:    ByteSlice(nil),
+			expected: nil,
+		},
+		{
+			name:     "Valid NullString",
+			input:    NullString{String: "valid_string", Valid: true},
+			expected: "valid_string",
+		},
+		{
+			name:     "Invalid NullString",
+			input:    NullString{String: "existing_string"},
+			expected: nil,
+		},
+		{
+			name:     "Valid NullInt64",
+			input:    NullInt64{Int64: 64, Valid: true},
+			expected: int64(64),
+		},
+		{
+			name:     "Valid NullInt32",
+			input:    NullInt32{Int32: 32, Valid: true},
+			expected: int64(32),
+		},
+		{
+			name:     "Valid NullInt16",
+			input:    NullInt16{Int16: 16, Valid: true},
+			expected: int64(16),
+		},
+		{
+			name:     "Valid NullByte",
+			input:    NullByte{Byte: 8, Valid: true},
+			expected: int64(8),
+		},
+		{
+			name:     "Valid NullFloat64",
+			input:    NullFloat64{Float64: 6.4, Valid: true},
+			expected: 6.4,
+		},
+		{
+			name:     "Valid NullBool",
+			input:    NullBool{Bool: true, Valid: true},
+			expected: true,
+		},
+		{
+			name:     "Valid NullTime",
+			input:    NullTime{Time: time.Date(1978, 12, 30, 0, 0, 0, 0, time.UTC), Valid: true},
+			expected: time.Date(1978, 12, 30, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "Invalid NullTime",
+			input:    NullTime{},
+			expected: nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Act
+			result, err := test.input.Value()
+
+			// Assert
+			assert.Nil(t, err)
+			assert.Equal(t, test.expected, result)
+		})
+	}
+}
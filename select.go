@@ -0,0 +1,172 @@
+package goscanql
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const (
+	// tableTagOption is the tag option used to declare the table that a nested struct is selected
+	// from, e.g. `sql:"pets,table=pet,on=account.id=pet.account_id"`.
+	tableTagOption = "table"
+
+	// asTagOption is the tag option used to alias the table of a nested struct (where the table
+	// is joined more than once), e.g. `sql:"colour,table=colour,as=pet_colour,on=..."`.
+	asTagOption = "as"
+
+	// onTagOption is the tag option used to declare the condition that the table of a nested
+	// struct is joined on, e.g. `sql:"pets,table=pet,on=account.id=pet.account_id"`.
+	onTagOption = "on"
+)
+
+// TableNamer can be implemented by a struct to declare the table that it is selected from. This
+// is required of the type provided to BuildSelect, and can be used by nested structs in place of
+// the table tag option.
+type TableNamer interface {
+	Table() string
+}
+
+// join represents a single table joined to a select statement.
+type join struct {
+
+	// table is the name of the joined table.
+	table string
+
+	// alias is the alias of the joined table (the same as table where it isn't aliased).
+	alias string
+
+	// on is the condition that the table is joined on.
+	on string
+}
+
+// tableName returns the name of the table declared by the provided type (t) implementing
+// TableNamer, and whether it does.
+func tableName(t reflect.Type) (string, bool) {
+	v := reflect.New(t)
+
+	if namer, ok := v.Interface().(TableNamer); ok {
+		return namer.Table(), true
+	}
+
+	if namer, ok := v.Elem().Interface().(TableNamer); ok {
+		return namer.Table(), true
+	}
+
+	return "", false
+}
+
+// collectJoins walks the fields of the provided type (t) in the order that they are declared and
+// returns each table that must be joined to select them, adding the alias of each to tables
+// (keyed by prefix).
+func collectJoins(t reflect.Type, prefix string, tables map[string]string, o *options) ([]join, error) {
+	joins := make([]join, 0)
+
+	for _, field := range structFields(t, o) {
+		if field.kind != oneToOneKind && field.kind != oneToManyKind {
+			continue
+		}
+
+		name := buildReferenceName(prefix, field.tag.name)
+
+		table, ok := field.tag.get(tableTagOption)
+		if !ok {
+			table, ok = tableName(field.elemType())
+		}
+
+		if ok {
+			on, ok := field.tag.get(onTagOption)
+			if !ok {
+				return nil, fmt.Errorf("goscanql: no join condition (%s) provided for table %s of field %s", onTagOption, table, name)
+			}
+
+			alias, ok := field.tag.get(asTagOption)
+			if !ok {
+				alias = table
+			}
+
+			joins = append(joins, join{table: table, alias: alias, on: on})
+			tables[name] = alias
+		}
+
+		if kindOf(field.elemType(), o) != oneToOneKind {
+			continue
+		}
+
+		children, err := collectJoins(field.elemType(), name, tables, o)
+		if err != nil {
+			return nil, err
+		}
+
+		joins = append(joins, children...)
+	}
+
+	return joins, nil
+}
+
+// BuildSelect builds a SELECT statement (in the provided dialect) that selects every column that
+// goscanql expects to read for the type T, so that the result can be scanned back with
+// RowsToStructs.
+//
+// T must implement TableNamer to declare the table that it is selected from. Each nested struct
+// that is selected from its own table is LEFT JOINed, and must declare its table with the table
+// tag option (or by implementing TableNamer), along with the condition it is joined on with the
+// on tag option, for example:
+//
+//	type Account struct {
+//		ID   int   `sql:"id"`
+//		Pets []Pet `sql:"pets,table=pet,on=account.id = pet.account_id"`
+//	}
+//
+//	func (Account) Table() string { return "account" }
+//
+// A joined table can be aliased using the as tag option. Nested structs that don't declare a table
+// are selected from the table of their parent.
+func BuildSelect[T any](dialect Dialect, opts ...Option) (string, error) {
+	var zero T
+
+	o := newOptions(opts)
+
+	err := validateType(zero, o)
+	if err != nil {
+		return "", err
+	}
+
+	t := getPointerRootType(reflect.TypeOf(zero))
+
+	table, ok := tableName(t)
+	if !ok {
+		return "", fmt.Errorf("goscanql: no table provided for %s, it must implement TableNamer", t.String())
+	}
+
+	tables := map[string]string{
+		"": table,
+	}
+
+	joins, err := collectJoins(t, "", tables, o)
+	if err != nil {
+		return "", err
+	}
+
+	b := strings.Builder{}
+
+	b.WriteString("SELECT ")
+	b.WriteString(strings.Join(selectExpressions(collectColumns(t, "", o), tables, dialect.Quote), ", "))
+	b.WriteString(" FROM ")
+	b.WriteString(dialect.Quote(table))
+
+	for _, j := range joins {
+		b.WriteString(" LEFT JOIN ")
+		b.WriteString(dialect.Quote(j.table))
+
+		if j.alias != j.table {
+			b.WriteString(" AS ")
+			b.WriteString(dialect.Quote(j.alias))
+		}
+
+		b.WriteString(" ON ")
+		b.WriteString(j.on)
+	}
+
+	return b.String(), nil
+}
@@ -0,0 +1,148 @@
+package goscanql
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+type selectTestColour struct {
+	Name string `sql:"name"`
+}
+
+type selectTestPet struct {
+	Name   string            `sql:"name"`
+	Colour *selectTestColour `sql:"colour,table=colour,as=pet_colour,on=pet_colour.name = pet.colour_name"`
+}
+
+func (selectTestPet) Table() string {
+	return "pet"
+}
+
+type selectTestAddress struct {
+	Street string `sql:"street"`
+}
+
+type selectTestAccount struct {
+	ID      int               `sql:"id"`
+	Address selectTestAddress `sql:"address"`
+	Aliases []string          `sql:"alias,table=account_alias,on=account.id = account_alias.account_id"`
+	Pets    []selectTestPet   `sql:"pets,on=account.id = pet.account_id"`
+}
+
+func (*selectTestAccount) Table() string {
+	return "account"
+}
+
+func TestBuildSelect(t *testing.T) {
+	tests := []struct {
+		name     string
+		dialect  Dialect
+		expected string
+	}{
+		{
+			name:    "Postgres",
+			dialect: Postgres,
+			expected: `SELECT "account"."id" AS "id", "account"."address_street" AS "address_street", ` +
+				`"account_alias"."alias" AS "alias", "pet"."name" AS "pets_name", "pet_colour"."name" AS "pets_colour_name" ` +
+				`FROM "account" ` +
+				`LEFT JOIN "account_alias" ON account.id = account_alias.account_id ` +
+				`LEFT JOIN "pet" ON account.id = pet.account_id ` +
+				`LEFT JOIN "colour" AS "pet_colour" ON pet_colour.name = pet.colour_name`,
+		},
+		{
+			name:    "MySQL",
+			dialect: MySQL,
+			expected: "SELECT `account`.`id` AS `id`, `account`.`address_street` AS `address_street`, " +
+				"`account_alias`.`alias` AS `alias`, `pet`.`name` AS `pets_name`, `pet_colour`.`name` AS `pets_colour_name` " +
+				"FROM `account` " +
+				"LEFT JOIN `account_alias` ON account.id = account_alias.account_id " +
+				"LEFT JOIN `pet` ON account.id = pet.account_id " +
+				"LEFT JOIN `colour` AS `pet_colour` ON pet_colour.name = pet.colour_name",
+		},
+		{
+			name:    "SQLite",
+			dialect: SQLite,
+			expected: `SELECT "account"."id" AS "id", "account"."address_street" AS "address_street", ` +
+				`"account_alias"."alias" AS "alias", "pet"."name" AS "pets_name", "pet_colour"."name" AS "pets_colour_name" ` +
+				`FROM "account" ` +
+				`LEFT JOIN "account_alias" ON account.id = account_alias.account_id ` +
+				`LEFT JOIN "pet" ON account.id = pet.account_id ` +
+				`LEFT JOIN "colour" AS "pet_colour" ON pet_colour.name = pet.colour_name`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Act
+			result, err := BuildSelect[selectTestAccount](test.dialect)
+
+			// Assert
+			assert.Nil(t, err)
+			assert.Equal(t, test.expected, result)
+		})
+	}
+}
+
+func TestBuildSelect_Errors(t *testing.T) {
+	type noTable struct {
+		ID int `sql:"id"`
+	}
+
+	type missingOn struct {
+		selectTestAccount
+		Pets []selectTestPet `sql:"pets"`
+	}
+
+	// Act
+	_, noTableErr := BuildSelect[noTable](Postgres)
+	_, missingOnErr := BuildSelect[missingOn](Postgres)
+
+	// Assert
+	assert.Equal(t, fmt.Errorf("goscanql: no table provided for goscanql.noTable, it must implement TableNamer"), noTableErr)
+	assert.Equal(t, fmt.Errorf("goscanql: no join condition (on) provided for table pet of field pets"), missingOnErr)
+}
+
+func TestBuildSelect_ScansBack(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		panic(err)
+	}
+
+	query, err := BuildSelect[selectTestAccount](Postgres)
+	if err != nil {
+		panic(err)
+	}
+
+	inputRows := sqlmock.NewRows([]string{"id", "address_street", "alias", "pets_name", "pets_colour_name"})
+	inputRows.AddRow(1, "Mulberry Lane", "Duchess", "Babou", "tan")
+	inputRows.AddRow(1, "Mulberry Lane", "Duchess", "Gustavo", nil)
+
+	mock.ExpectQuery(regexp.QuoteMeta(query)).WillReturnRows(inputRows)
+
+	rows, err := db.Query(query)
+	if err != nil {
+		panic(err)
+	}
+
+	// Act
+	result, err := RowsToStructs[selectTestAccount](rows)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []selectTestAccount{
+		{
+			ID:      1,
+			Address: selectTestAddress{Street: "Mulberry Lane"},
+			Aliases: []string{"Duchess"},
+			Pets: []selectTestPet{
+				{Name: "Babou", Colour: &selectTestColour{Name: "tan"}},
+				{Name: "Gustavo"},
+			},
+		},
+	}, result)
+}
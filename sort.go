@@ -0,0 +1,429 @@
+package goscanql
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	// timeType is the type of time.Time, which goscanql treats as a single value rather than
+	// as a struct.
+	timeType = reflect.TypeOf(time.Time{})
+)
+
+const (
+	// orderByTagOption is the tag option used to sort the children of a one-to-many relationship
+	// by one of their fields, e.g. `sql:"pets,orderby=name desc"`.
+	orderByTagOption = "orderby"
+)
+
+// sortKey represents a single key that a slice of entities can be sorted by.
+type sortKey struct {
+
+	// name is the name of the field to sort by (including the prefixes of any one-to-one
+	// relationships, e.g. colour_name).
+	name string
+
+	// descending determines whether the slice should be sorted in descending order (rather than
+	// ascending order).
+	descending bool
+}
+
+// parseSortKeys parses the provided raw sort keys, where each raw key is the name of a field,
+// optionally followed by a direction (asc or desc), e.g. "name desc".
+func parseSortKeys(raw ...string) ([]sortKey, error) {
+	keys := make([]sortKey, 0, len(raw))
+
+	for _, r := range raw {
+		parts := strings.Fields(r)
+
+		if len(parts) == 0 {
+			keys = append(keys, sortKey{})
+			continue
+		}
+
+		if len(parts) > 2 {
+			return nil, fmt.Errorf("goscanql: invalid sort key \"%s\"", r)
+		}
+
+		key := sortKey{
+			name: parts[0],
+		}
+
+		if len(parts) == 2 {
+			switch strings.ToLower(parts[1]) {
+			case "asc":
+			case "desc":
+				key.descending = true
+			default:
+				return nil, fmt.Errorf("goscanql: invalid sort direction \"%s\" in sort key \"%s\"", parts[1], r)
+			}
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// sortSlice will sort the provided slice (v) of entities by the provided keys. The sort is stable,
+// so entities that are equal (according to the keys) remain in the order in which they were
+// first seen.
+//
+// Where the slice holds entities that aren't structs (e.g. []string), the entities themselves
+// are sorted by and the name of the key is ignored.
+func sortSlice(v reflect.Value, keys []sortKey, o *options) error {
+	var err error
+
+	sort.SliceStable(v.Interface(), func(i, j int) bool {
+		if err != nil {
+			return false
+		}
+
+		for _, key := range keys {
+			a, e := sortValue(v.Index(i), key.name, o)
+			if e != nil {
+				err = e
+				return false
+			}
+
+			b, e := sortValue(v.Index(j), key.name, o)
+			if e != nil {
+				err = e
+				return false
+			}
+
+			c, e := compareValues(a, b)
+			if e != nil {
+				err = e
+				return false
+			}
+
+			if c == 0 {
+				continue
+			}
+
+			return (c < 0) != key.descending
+		}
+
+		return false
+	})
+
+	return err
+}
+
+// sortValue returns the value that the provided entity (v) should be sorted by for the field
+// with the provided name. An invalid reflect.Value is returned where the value is nil.
+func sortValue(v reflect.Value, name string, o *options) (reflect.Value, error) {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return reflect.Value{}, nil
+		}
+
+		v = v.Elem()
+	}
+
+	if !isOneToOne(v, o) {
+		return v, nil
+	}
+
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		fieldName, ok := o.fieldName(t, i)
+		if !ok {
+			continue
+		}
+
+		if fieldName == name {
+			return v.Field(i), nil
+		}
+
+		// if the field is a one-to-one relationship that the name is prefixed with, search
+		// the nested struct
+		if strings.HasPrefix(name, fieldName+"_") && isOneToOneType(getPointerRootType(t.Field(i).Type), o) {
+			return sortValue(v.Field(i), strings.TrimPrefix(name, fieldName+"_"), o)
+		}
+	}
+
+	return reflect.Value{}, fmt.Errorf("goscanql: unable to sort %s by unknown field \"%s\"", t.String(), name)
+}
+
+// validateSortKeys checks that the sort keys provided with WithSortBy (for the root type, t) and
+// with the orderby option of each one-to-many relationship of t (or of its children) can be
+// parsed, and that they name fields of the entities that they sort.
+func validateSortKeys(t reflect.Type, o *options) error {
+	if o != nil && len(o.sortBy) > 0 {
+		keys, err := parseSortKeys(o.sortBy...)
+		if err != nil {
+			return err
+		}
+
+		err = validateSortFields(t, keys, o)
+		if err != nil {
+			return err
+		}
+	}
+
+	return traverseType(t, func(t reflect.Type) error {
+		if t.Kind() != reflect.Struct {
+			return nil
+		}
+
+		for _, sf := range structFields(t, o) {
+			raw, ok := sf.tag.get(orderByTagOption)
+			if !ok || sf.kind != oneToManyKind {
+				continue
+			}
+
+			keys, err := parseSortKeys(strings.Split(raw, ";")...)
+			if err != nil {
+				return err
+			}
+
+			err = validateSortFields(getPointerRootType(sf.field.Type).Elem(), keys, o)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}, o)
+}
+
+// validateSortFields checks that each of the provided keys names a field of the provided type
+// (t) of entity that it sorts.
+func validateSortFields(t reflect.Type, keys []sortKey, o *options) error {
+	for _, key := range keys {
+		err := validateSortField(t, key.name, o)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateSortField checks that the field with the provided name can be found by sortValue in
+// entities of the provided type (t).
+func validateSortField(t reflect.Type, name string, o *options) error {
+	t = getPointerRootType(t)
+
+	if !isOneToOneType(t, o) {
+		return nil
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		fieldName, ok := o.fieldName(t, i)
+		if !ok {
+			continue
+		}
+
+		if fieldName == name {
+			return nil
+		}
+
+		// if the field is a one-to-one relationship that the name is prefixed with, search
+		// the nested struct
+		if strings.HasPrefix(name, fieldName+"_") && isOneToOneType(getPointerRootType(t.Field(i).Type), o) {
+			return validateSortField(t.Field(i).Type, strings.TrimPrefix(name, fieldName+"_"), o)
+		}
+	}
+
+	return fmt.Errorf("goscanql: unable to sort %s by unknown field \"%s\"", t.String(), name)
+}
+
+// isOneToOne returns true if the provided value is a struct that goscanql would treat as a
+// one-to-one relationship (rather than as a single value).
+func isOneToOne(v reflect.Value, o *options) bool {
+	return isOneToOneType(v.Type(), o)
+}
+
+// isOneToOneType returns true if the provided type is a struct that goscanql would treat as
+// a one-to-one relationship (rather than as a single value).
+func isOneToOneType(t reflect.Type, o *options) bool {
+	return t.Kind() == reflect.Struct && kindOf(t, o) == oneToOneKind
+}
+
+// compareValues compares the provided values, returning a negative number if a is less than b,
+// a positive number if a is greater than b, or 0 if they are equal. Nil (invalid) values are
+// treated as being less than any other value.
+func compareValues(a, b reflect.Value) (int, error) {
+	a, b = indirectValue(a), indirectValue(b)
+
+	switch {
+	case !a.IsValid() && !b.IsValid():
+		return 0, nil
+	case !a.IsValid():
+		return -1, nil
+	case !b.IsValid():
+		return 1, nil
+	}
+
+	if a.Type() == timeType {
+		return a.Interface().(time.Time).Compare(b.Interface().(time.Time)), nil
+	}
+
+	// compare values that implement driver.Valuer (e.g. NullString) by their underlying values
+	if a.CanAddr() && a.Addr().Type().Implements(valuerType) {
+		av, err := driverValue(a)
+		if err != nil {
+			return 0, err
+		}
+
+		bv, err := driverValue(b)
+		if err != nil {
+			return 0, err
+		}
+
+		return compareValues(reflect.ValueOf(av), reflect.ValueOf(bv))
+	}
+
+	if a.CanAddr() && implementsScanner(a.Addr().Type()) {
+		return bytes.Compare(a.Addr().Interface().(Scanner).ID(), b.Addr().Interface().(Scanner).ID()), nil
+	}
+
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return compareOrdered(a.Int(), b.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return compareOrdered(a.Uint(), b.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return compareOrdered(a.Float(), b.Float()), nil
+	case reflect.String:
+		return strings.Compare(a.String(), b.String()), nil
+	case reflect.Bool:
+		return compareOrdered(boolToInt(a.Bool()), boolToInt(b.Bool())), nil
+	case reflect.Slice:
+		if a.Type().Elem().Kind() == reflect.Uint8 {
+			return bytes.Compare(a.Bytes(), b.Bytes()), nil
+		}
+	}
+
+	return 0, fmt.Errorf("goscanql: unable to sort by value of type %s", a.Type().String())
+}
+
+// indirectValue will dereference the provided value until a non-pointer (or non-interface)
+// value is reached. An invalid reflect.Value is returned if a nil is reached.
+func indirectValue(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+
+		v = v.Elem()
+	}
+
+	return v
+}
+
+// compareOrdered compares two ordered values, returning -1, 0 or 1.
+func compareOrdered[T int | int64 | uint64 | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+
+	return 0
+}
+
+// boolToInt converts a bool to an int so that it can be compared (false being less than true).
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+
+	return 0
+}
+
+// usesSorting returns true if entities of the provided type (t) are sorted once they have been
+// scanned, i.e. o has sort keys or a one-to-many relationship of the type (or of its children) is
+// tagged with the orderby option.
+func usesSorting(t reflect.Type, o *options) bool {
+	if o != nil && len(o.sortBy) > 0 {
+		return true
+	}
+
+	found := false
+
+	_ = traverseType(t, func(t reflect.Type) error {
+		if t.Kind() != reflect.Struct {
+			return nil
+		}
+
+		for _, sf := range structFields(t, o) {
+			found = found || sf.tag.has(orderByTagOption)
+		}
+
+		return nil
+	}, o)
+
+	return found
+}
+
+// sortEntities will sort the children of each of the one-to-many relationships of the provided
+// value (v) that have been tagged with the orderby option, and recursively do the same for all
+// of the value's children.
+func sortEntities(v reflect.Value, o *options) error {
+	v = indirectValue(v)
+
+	if !v.IsValid() {
+		return nil
+	}
+
+	if v.Kind() == reflect.Slice {
+		for i := 0; i < v.Len(); i++ {
+			err := sortEntities(v.Index(i), o)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	if !isOneToOne(v, o) {
+		return nil
+	}
+
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		fieldTag, ok := o.fieldTag(t, i)
+		if !ok {
+			continue
+		}
+
+		field := indirectValue(v.Field(i))
+		if !field.IsValid() {
+			continue
+		}
+
+		if field.Kind() == reflect.Slice && !implementsScanner(reflect.PointerTo(field.Type())) {
+			if raw, ok := fieldTag.get(orderByTagOption); ok {
+				keys, err := parseSortKeys(strings.Split(raw, ";")...)
+				if err != nil {
+					return err
+				}
+
+				err = sortSlice(field, keys, o)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		err := sortEntities(field, o)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,231 @@
+package goscanql
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSortKeys(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       []string
+		expected    []sortKey
+		expectedErr error
+	}{
+		{
+			name:  "GivenNameOnly_ThenAscending",
+			input: []string{"name"},
+			expected: []sortKey{
+				{name: "name"},
+			},
+		},
+		{
+			name:  "GivenDirections_ThenDirectionsApplied",
+			input: []string{"name DESC", "age asc"},
+			expected: []sortKey{
+				{name: "name", descending: true},
+				{name: "age"},
+			},
+		},
+		{
+			name:  "GivenEmptyKey_ThenEmptyKeyReturned",
+			input: []string{""},
+			expected: []sortKey{
+				{},
+			},
+		},
+		{
+			name:        "GivenUnknownDirection_ThenErrorReturned",
+			input:       []string{"name sideways"},
+			expectedErr: fmt.Errorf("goscanql: invalid sort direction \"sideways\" in sort key \"name sideways\""),
+		},
+		{
+			name:        "GivenTooManyParts_ThenErrorReturned",
+			input:       []string{"name desc please"},
+			expectedErr: fmt.Errorf("goscanql: invalid sort key \"name desc please\""),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Act
+			result, err := parseSortKeys(test.input...)
+
+			// Assert
+			assert.Equal(t, test.expectedErr, err)
+			assert.Equal(t, test.expected, result)
+		})
+	}
+}
+
+func TestValidateSortKeys(t *testing.T) {
+	type colour struct {
+		Name string `sql:"name"`
+	}
+
+	type pet struct {
+		Name   string  `sql:"name"`
+		Colour *colour `sql:"colour"`
+	}
+
+	type account struct {
+		Name    string   `sql:"name"`
+		Colour  colour   `sql:"colour"`
+		Pets    []*pet   `sql:"pets,orderby=colour_name desc;name"`
+		Aliases []string `sql:"alias,orderby"`
+	}
+
+	type misspelt struct {
+		Pets []pet `sql:"pets,orderby=nmae"`
+	}
+
+	tests := []struct {
+		name        string
+		input       interface{}
+		opts        []Option
+		expectedErr error
+	}{
+		{
+			name:  "GivenKnownFields_ThenNoError",
+			input: account{},
+			opts:  []Option{WithSortBy("colour_name", "name desc")},
+		},
+		{
+			name:        "GivenSortByUnknownField_ThenErrorReturned",
+			input:       account{},
+			opts:        []Option{WithSortBy("nmae")},
+			expectedErr: fmt.Errorf("goscanql: unable to sort goscanql.account by unknown field \"nmae\""),
+		},
+		{
+			name:        "GivenSortByUnknownNestedField_ThenErrorReturned",
+			input:       account{},
+			opts:        []Option{WithSortBy("colour_hue")},
+			expectedErr: fmt.Errorf("goscanql: unable to sort goscanql.colour by unknown field \"hue\""),
+		},
+		{
+			name:        "GivenSortByInvalidDirection_ThenErrorReturned",
+			input:       account{},
+			opts:        []Option{WithSortBy("name sideways")},
+			expectedErr: fmt.Errorf("goscanql: invalid sort direction \"sideways\" in sort key \"name sideways\""),
+		},
+		{
+			name:        "GivenOrderByUnknownField_ThenErrorReturned",
+			input:       misspelt{},
+			expectedErr: fmt.Errorf("goscanql: unable to sort goscanql.pet by unknown field \"nmae\""),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Act
+			err := validateType(test.input, newOptions(test.opts))
+
+			// Assert
+			assert.Equal(t, test.expectedErr, err)
+		})
+	}
+}
+
+func TestCompareValues(t *testing.T) {
+	tests := []struct {
+		name        string
+		a           interface{}
+		b           interface{}
+		expected    int
+		expectedErr error
+	}{
+		{
+			name:     "Int_Less",
+			a:        1,
+			b:        2,
+			expected: -1,
+		},
+		{
+			name:     "Uint_Greater",
+			a:        uint8(3),
+			b:        uint8(2),
+			expected: 1,
+		},
+		{
+			name:     "Float_Equal",
+			a:        1.5,
+			b:        1.5,
+			expected: 0,
+		},
+		{
+			name:     "String_Less",
+			a:        "apple",
+			b:        "banana",
+			expected: -1,
+		},
+		{
+			name:     "Bool_Greater",
+			a:        true,
+			b:        false,
+			expected: 1,
+		},
+		{
+			name:     "Time_Less",
+			a:        time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+			b:        time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC),
+			expected: -1,
+		},
+		{
+			name:     "Pointer_Dereferenced",
+			a:        referenceField(2),
+			b:        referenceField(1),
+			expected: 1,
+		},
+		{
+			name:     "NilPointer_Less",
+			a:        (*int)(nil),
+			b:        referenceField(1),
+			expected: -1,
+		},
+		{
+			name:     "Scanner_ComparedByID",
+			a:        referenceField(exampleScanner{id: "a"}),
+			b:        referenceField(exampleScanner{id: "b"}),
+			expected: -1,
+		},
+		{
+			name:     "Valuer_ComparedByValue",
+			a:        referenceField(NullInt64{Int64: 10, Valid: true}),
+			b:        referenceField(NullInt64{Int64: 9, Valid: true}),
+			expected: 1,
+		},
+		{
+			name:     "InvalidValuer_Less",
+			a:        referenceField(NullInt64{}),
+			b:        referenceField(NullInt64{Int64: 9, Valid: true}),
+			expected: -1,
+		},
+		{
+			name:     "ByteSlice_ComparedByBytes",
+			a:        ByteSlice("b"),
+			b:        ByteSlice("a"),
+			expected: 1,
+		},
+		{
+			name:        "Unsupported_ProducesError",
+			a:           []int{},
+			b:           []int{},
+			expectedErr: fmt.Errorf("goscanql: unable to sort by value of type []int"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Act
+			result, err := compareValues(reflect.ValueOf(test.a), reflect.ValueOf(test.b))
+
+			// Assert
+			assert.Equal(t, test.expectedErr, err)
+			assert.Equal(t, test.expected, result)
+		})
+	}
+}
@@ -0,0 +1,498 @@
+package goscanql
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// RowSource represents a source of flat rows (other than *sql.Rows) that goscanql can map and
+// aggregate into structs, e.g. a CSV export or a slice of decoded JSON objects.
+type RowSource interface {
+
+	// Columns returns the names of the columns of the rows.
+	Columns() ([]string, error)
+
+	// Next advances the RowSource to the next row, returning false when there are no more rows.
+	// Where the next row can't be read, Next should return true and Values the error.
+	Next() bool
+
+	// Values returns the values of the current row, in the order of the columns. Nil values are
+	// treated as NULL.
+	Values() ([]interface{}, error)
+}
+
+// scannerValueTypes maps each of the goscanql Scanners to the type of value that they expect to
+// receive, so that values of other types (e.g. the strings of a CSV) can be converted before
+// being scanned.
+var scannerValueTypes = map[reflect.Type]reflect.Type{
+	reflect.TypeOf(&ByteSlice{}):   reflect.TypeOf([]byte{}),
+	reflect.TypeOf(&NullString{}):  reflect.TypeOf(""),
+	reflect.TypeOf(&NullInt64{}):   reflect.TypeOf(int64(0)),
+	reflect.TypeOf(&NullInt32{}):   reflect.TypeOf(int32(0)),
+	reflect.TypeOf(&NullInt16{}):   reflect.TypeOf(int16(0)),
+	reflect.TypeOf(&NullByte{}):    reflect.TypeOf(byte(0)),
+	reflect.TypeOf(&NullFloat64{}): reflect.TypeOf(float64(0)),
+	reflect.TypeOf(&NullBool{}):    reflect.TypeOf(false),
+	reflect.TypeOf(&NullTime{}):    timeType,
+}
+
+// timeLayouts are the layouts that strings are parsed with (in order) when assigned to a
+// time.Time.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+}
+
+// sourceReader reads the rows of a RowSource with the next and scan functions used by scanAll. As
+// each row is scanned more than once (see fields.scan), the values of the current row are cached
+// so that Values is only called once per row.
+type sourceReader struct {
+	source RowSource
+	values []interface{}
+	err    error
+	read   bool
+}
+
+// next advances the RowSource to the next row, discarding the values of the current row.
+func (sr *sourceReader) next() bool {
+	sr.values, sr.err, sr.read = nil, nil, false
+	return sr.source.Next()
+}
+
+// scan assigns the values of the current row of the RowSource to the provided destinations.
+func (sr *sourceReader) scan(dest ...interface{}) error {
+	if !sr.read {
+		sr.values, sr.err = sr.source.Values()
+		sr.read = true
+	}
+
+	if sr.err != nil {
+		return sr.err
+	}
+
+	if len(sr.values) != len(dest) {
+		return fmt.Errorf("goscanql: expected %d values in row, got %d", len(dest), len(sr.values))
+	}
+
+	for i, value := range sr.values {
+		err := assignValue(dest[i], value)
+		if err != nil {
+			return fmt.Errorf("goscanql: unable to assign value of column %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// assignValue assigns the provided value (src) to the provided destination (dest), which must be
+// a pointer (or a sql.Scanner). This is the RowSource equivalent of the conversion that
+// database/sql performs during a scan, with strings additionally being parsed into numbers,
+// bools and times (so that text sources such as CSVs can be used).
+func assignValue(dest, src interface{}) error {
+	scanner, ok := dest.(sql.Scanner)
+	if !ok {
+		return assignReflect(reflect.ValueOf(dest).Elem(), src)
+	}
+
+	// convert the value to the type expected by the goscanql Scanners
+	if want, ok := scannerValueTypes[reflect.TypeOf(dest)]; ok && src != nil && reflect.TypeOf(src) != want {
+		converted := reflect.New(want).Elem()
+
+		err := assignReflect(converted, src)
+		if err != nil {
+			return err
+		}
+
+		src = converted.Interface()
+	}
+
+	return scanner.Scan(src)
+}
+
+// assignReflect assigns the provided value (src) to the provided value (dv), converting it where
+// necessary. Nil values set dv to its zero value.
+func assignReflect(dv reflect.Value, src interface{}) error {
+	if src == nil {
+		dv.Set(reflect.Zero(dv.Type()))
+		return nil
+	}
+
+	if dv.Kind() == reflect.Pointer {
+		if dv.IsNil() {
+			dv.Set(reflect.New(dv.Type().Elem()))
+		}
+
+		return assignReflect(dv.Elem(), src)
+	}
+
+	sv := reflect.ValueOf(src)
+
+	if sv.Type().AssignableTo(dv.Type()) {
+		dv.Set(sv)
+		return nil
+	}
+
+	if b, ok := src.([]byte); ok {
+		if dv.Kind() == reflect.Slice && dv.Type().Elem().Kind() == reflect.Uint8 {
+			dv.SetBytes(append([]byte{}, b...))
+			return nil
+		}
+
+		return assignString(dv, string(b))
+	}
+
+	if s, ok := src.(string); ok {
+		return assignString(dv, s)
+	}
+
+	if isNumber(sv.Kind()) && isNumber(dv.Kind()) {
+		return assignNumber(dv, sv)
+	}
+
+	// values of other types are assigned to strings (and byte slices) in their default format
+	switch {
+	case dv.Kind() == reflect.String:
+		dv.SetString(fmt.Sprint(src))
+		return nil
+
+	case dv.Kind() == reflect.Slice && dv.Type().Elem().Kind() == reflect.Uint8:
+		dv.SetBytes([]byte(fmt.Sprint(src)))
+		return nil
+	}
+
+	return fmt.Errorf("unable to assign %s to %s", sv.Type(), dv.Type())
+}
+
+// assignString parses the provided string (s) into the provided value (dv), based on its type.
+func assignString(dv reflect.Value, s string) error {
+	if dv.Type() == timeType {
+		for _, layout := range timeLayouts {
+			t, err := time.Parse(layout, s)
+			if err == nil {
+				dv.Set(reflect.ValueOf(t))
+				return nil
+			}
+		}
+
+		return fmt.Errorf("unable to parse %q as a time", s)
+	}
+
+	switch dv.Kind() {
+	case reflect.String:
+		dv.SetString(s)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, dv.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		dv.SetInt(i)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, dv.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		dv.SetUint(u)
+
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, dv.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		dv.SetFloat(f)
+
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+
+		dv.SetBool(b)
+
+	case reflect.Slice:
+		if dv.Type().Elem().Kind() != reflect.Uint8 {
+			return fmt.Errorf("unable to assign string to %s", dv.Type())
+		}
+
+		dv.SetBytes([]byte(s))
+
+	default:
+		return fmt.Errorf("unable to assign string to %s", dv.Type())
+	}
+
+	return nil
+}
+
+// assignNumber assigns the provided number (sv) to the provided number (dv), returning an error
+// (rather than truncating or wrapping, as a conversion would) where the value can't be held by dv,
+// such as a fractional float assigned to an int, or an int that overflows it.
+func assignNumber(dv, sv reflect.Value) error {
+	switch dv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+
+		switch sv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			i = sv.Int()
+
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if sv.Uint() > math.MaxInt64 {
+				return lossyError(dv, sv)
+			}
+
+			i = int64(sv.Uint())
+
+		default:
+			f := sv.Float()
+			if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+				return lossyError(dv, sv)
+			}
+
+			i = int64(f)
+		}
+
+		if dv.OverflowInt(i) {
+			return lossyError(dv, sv)
+		}
+
+		dv.SetInt(i)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var u uint64
+
+		switch sv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if sv.Int() < 0 {
+				return lossyError(dv, sv)
+			}
+
+			u = uint64(sv.Int())
+
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			u = sv.Uint()
+
+		default:
+			f := sv.Float()
+			if f != math.Trunc(f) || f < 0 || f >= math.MaxUint64 {
+				return lossyError(dv, sv)
+			}
+
+			u = uint64(f)
+		}
+
+		if dv.OverflowUint(u) {
+			return lossyError(dv, sv)
+		}
+
+		dv.SetUint(u)
+
+	default:
+		var f float64
+
+		switch sv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			f = float64(sv.Int())
+
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			f = float64(sv.Uint())
+
+		default:
+			f = sv.Float()
+		}
+
+		if dv.OverflowFloat(f) {
+			return lossyError(dv, sv)
+		}
+
+		dv.SetFloat(f)
+	}
+
+	return nil
+}
+
+// lossyError returns the error for a number (sv) that can't be assigned to dv without loss.
+func lossyError(dv, sv reflect.Value) error {
+	return fmt.Errorf("unable to assign %s %v to %s without loss", sv.Type(), sv.Interface(), dv.Type())
+}
+
+// isNumber returns true if the provided kind is an integer or a float.
+func isNumber(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+
+	return false
+}
+
+// csvSource is a RowSource that reads rows from a CSV, taking the names of the columns from the
+// header row. Empty cells are treated as NULL, unless emptyStrings is true.
+type csvSource struct {
+	reader       *csv.Reader
+	columns      []string
+	record       []string
+	err          error
+	emptyStrings bool
+}
+
+func (cs *csvSource) Columns() ([]string, error) {
+	if cs.columns != nil {
+		return cs.columns, nil
+	}
+
+	header, err := cs.reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("goscanql: csv has no header row")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	cs.columns = header
+	return cs.columns, nil
+}
+
+func (cs *csvSource) Next() bool {
+	cs.record, cs.err = cs.reader.Read()
+	return !errors.Is(cs.err, io.EOF)
+}
+
+func (cs *csvSource) Values() ([]interface{}, error) {
+	if cs.err != nil {
+		return nil, cs.err
+	}
+
+	values := make([]interface{}, len(cs.record))
+	for i, cell := range cs.record {
+		if cell != "" || cs.emptyStrings {
+			values[i] = cell
+		}
+	}
+
+	return values, nil
+}
+
+// mapSource is a RowSource that reads rows from a slice of maps, where each map is keyed by the
+// column names. The columns are the (sorted) union of the keys of every map, and keys missing
+// from a map are treated as NULL.
+type mapSource struct {
+	maps    []map[string]interface{}
+	columns []string
+	index   int
+}
+
+func newMapSource(maps []map[string]interface{}) *mapSource {
+	seen := map[string]bool{}
+	columns := make([]string, 0)
+
+	for _, m := range maps {
+		for column := range m {
+			if !seen[column] {
+				seen[column] = true
+				columns = append(columns, column)
+			}
+		}
+	}
+
+	sort.Strings(columns)
+
+	return &mapSource{
+		maps:    maps,
+		columns: columns,
+		index:   -1,
+	}
+}
+
+func (ms *mapSource) Columns() ([]string, error) {
+	return ms.columns, nil
+}
+
+func (ms *mapSource) Next() bool {
+	ms.index++
+	return ms.index < len(ms.maps)
+}
+
+func (ms *mapSource) Values() ([]interface{}, error) {
+	values := make([]interface{}, len(ms.columns))
+	for i, column := range ms.columns {
+		values[i] = ms.maps[ms.index][column]
+	}
+
+	return values, nil
+}
+
+// channelSource is a RowSource that reads rows from a channel until it is closed.
+type channelSource struct {
+	columns []string
+	rows    <-chan []interface{}
+	row     []interface{}
+}
+
+func (cs *channelSource) Columns() ([]string, error) {
+	return cs.columns, nil
+}
+
+func (cs *channelSource) Next() bool {
+	row, ok := <-cs.rows
+	cs.row = row
+
+	return ok
+}
+
+func (cs *channelSource) Values() ([]interface{}, error) {
+	return cs.row, nil
+}
+
+// FromSource will read each of the rows of the provided RowSource, and return a slice of Ts (the
+// provided type) as the result, aggregating them in the same way that RowsToStructs does. Values
+// is called once for each row.
+func FromSource[T any](source RowSource, opts ...Option) ([]T, error) {
+	columns, err := source.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	reader := &sourceReader{source: source}
+
+	return scanAll[T](columns, reader.next, reader.scan, newOptions(opts))
+}
+
+// FromCSV will read the CSV from the provided reader, and return a slice of Ts (the provided type)
+// as the result. The names of the columns are taken from the header row of the CSV, and empty
+// cells are treated as NULL (unless WithEmptyCSVStrings is provided). Cells are parsed into the
+// type of the field that they are mapped to, e.g. "42" is parsed into an int field as 42.
+func FromCSV[T any](r io.Reader, opts ...Option) ([]T, error) {
+	o := newOptions(opts)
+
+	return FromSource[T](&csvSource{reader: csv.NewReader(r), emptyStrings: o.emptyCSVStrings}, opts...)
+}
+
+// FromMaps will read each of the provided maps as a row (keyed by column name), and return a
+// slice of Ts (the provided type) as the result. Columns missing from a map are treated as NULL,
+// and values are converted to the type of the field that they are mapped to where necessary
+// (e.g. the float64s of decoded JSON can be mapped to int fields).
+func FromMaps[T any](maps []map[string]interface{}, opts ...Option) ([]T, error) {
+	return FromSource[T](newMapSource(maps), opts...)
+}
+
+// FromChannel will read rows (holding a value for each of the provided columns) from the provided
+// channel until it is closed, and return a slice of Ts (the provided type) as the result.
+func FromChannel[T any](columns []string, rows <-chan []interface{}, opts ...Option) ([]T, error) {
+	return FromSource[T](&channelSource{columns: columns, rows: rows}, opts...)
+}
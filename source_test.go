@@ -0,0 +1,297 @@
+package goscanql
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type sourceTestColour struct {
+	Red   int `sql:"red"`
+	Green int `sql:"green"`
+}
+
+type sourceTestPet struct {
+	Name   string            `sql:"name"`
+	Colour *sourceTestColour `sql:"colour"`
+}
+
+type sourceTestAccount struct {
+	ID          int             `sql:"id"`
+	Nickname    NullString      `sql:"nickname"`
+	Balance     NullFloat64     `sql:"balance"`
+	DateOfBirth time.Time       `sql:"date_of_birth"`
+	Active      bool            `sql:"active"`
+	Pets        []sourceTestPet `sql:"pets"`
+	Aliases     []string        `sql:"alias"`
+}
+
+var expectedSourceAccounts = []sourceTestAccount{
+	{
+		ID:          1,
+		Nickname:    NullString{String: "Duchess", Valid: true},
+		Balance:     NullFloat64{Float64: 12.5, Valid: true},
+		DateOfBirth: time.Date(1978, 12, 30, 0, 0, 0, 0, time.UTC),
+		Active:      true,
+		Pets: []sourceTestPet{
+			{Name: "Babou", Colour: &sourceTestColour{Red: 255, Green: 128}},
+			{Name: "Gustavo"},
+		},
+		Aliases: []string{"Randy"},
+	},
+	{
+		ID:          2,
+		DateOfBirth: time.Date(1987, 4, 24, 0, 0, 0, 0, time.UTC),
+	},
+}
+
+func TestFromCSV(t *testing.T) {
+	// Arrange
+	input := strings.Join([]string{
+		"id,nickname,balance,date_of_birth,active,pets_name,pets_colour_red,pets_colour_green,alias,ignored",
+		"1,Duchess,12.5,1978-12-30,true,Babou,255,128,Randy,x",
+		"1,Duchess,12.5,1978-12-30,true,Gustavo,,,Randy,y",
+		"2,,,1987-04-24T00:00:00Z,,,,,,",
+	}, "\n")
+
+	// Act
+	result, err := FromCSV[sourceTestAccount](strings.NewReader(input))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, expectedSourceAccounts, result)
+}
+
+func TestFromCSV_Errors(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expectedErr string
+	}{
+		{
+			name:        "GivenNoHeader_ThenErrorReturned",
+			input:       "",
+			expectedErr: "goscanql: csv has no header row",
+		},
+		{
+			name:        "GivenUnparsableValue_ThenErrorReturned",
+			input:       "id\nabc",
+			expectedErr: `goscanql: unable to assign value of column 0: strconv.ParseInt: parsing "abc": invalid syntax`,
+		},
+		{
+			name:        "GivenInconsistentRow_ThenErrorReturned",
+			input:       "id,alias\n1",
+			expectedErr: "record on line 2: wrong number of fields",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Act
+			_, err := FromCSV[sourceTestAccount](strings.NewReader(test.input))
+
+			// Assert
+			assert.EqualError(t, err, test.expectedErr)
+		})
+	}
+}
+
+func TestFromMaps(t *testing.T) {
+	// Arrange
+	input := []map[string]interface{}{
+		{
+			"id": float64(1), "nickname": "Duchess", "balance": 12.5, "date_of_birth": "1978-12-30T00:00:00Z",
+			"active": true, "pets_name": "Babou", "pets_colour_red": float64(255), "pets_colour_green": float64(128),
+			"alias": "Randy",
+		},
+		{
+			"id": float64(1), "nickname": "Duchess", "balance": 12.5, "date_of_birth": "1978-12-30T00:00:00Z",
+			"active": true, "pets_name": "Gustavo", "alias": "Randy",
+		},
+		{
+			"id": 2, "date_of_birth": time.Date(1987, 4, 24, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	// Act
+	result, err := FromMaps[sourceTestAccount](input)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, expectedSourceAccounts, result)
+}
+
+func TestFromChannel(t *testing.T) {
+	// Arrange
+	rows := make(chan []interface{}, 3)
+	rows <- []interface{}{1, "Babou", 255}
+	rows <- []interface{}{1, "Gustavo", nil}
+	rows <- []interface{}{2, nil, nil}
+	close(rows)
+
+	// Act
+	result, err := FromChannel[sourceTestAccount]([]string{"id", "pets_name", "pets_colour_red"}, rows)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []sourceTestAccount{
+		{
+			ID: 1,
+			Pets: []sourceTestPet{
+				{Name: "Babou", Colour: &sourceTestColour{Red: 255}},
+				{Name: "Gustavo"},
+			},
+		},
+		{ID: 2},
+	}, result)
+}
+
+func TestFromCSV_WithEmptyCSVStrings(t *testing.T) {
+	type account struct {
+		ID       int     `sql:"id"`
+		Nickname *string `sql:"nickname"`
+	}
+
+	input := "id,nickname\n1,\n2,Duchess"
+	empty, duchess := "", "Duchess"
+
+	// Act
+	result, err := FromCSV[account](strings.NewReader(input), WithEmptyCSVStrings())
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []account{{ID: 1, Nickname: &empty}, {ID: 2, Nickname: &duchess}}, result)
+}
+
+// countingSource is a RowSource that counts the number of times that Values is called.
+type countingSource struct {
+	*mapSource
+	calls int
+}
+
+func (cs *countingSource) Values() ([]interface{}, error) {
+	cs.calls++
+	return cs.mapSource.Values()
+}
+
+func TestFromSource_ValuesCalledOncePerRow(t *testing.T) {
+	// Arrange
+	source := &countingSource{mapSource: newMapSource([]map[string]interface{}{
+		{"id": 1, "pets_name": "Babou"},
+		{"id": 1, "pets_name": "Gustavo"},
+		{"id": 2},
+	})}
+
+	// Act
+	result, err := FromSource[sourceTestAccount](source)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Len(t, result, 2)
+	assert.Equal(t, 3, source.calls)
+}
+
+func TestAssignValue(t *testing.T) {
+	tests := []struct {
+		name        string
+		dest        interface{}
+		src         interface{}
+		expected    interface{}
+		expectedErr error
+	}{
+		{
+			name:     "GivenIntToString_ThenFormatted",
+			dest:     new(string),
+			src:      42,
+			expected: "42",
+		},
+		{
+			name:     "GivenBytesToInt_ThenParsed",
+			dest:     new(int),
+			src:      []byte("42"),
+			expected: 42,
+		},
+		{
+			name:     "GivenStringToNullInt64_ThenParsed",
+			dest:     &NullInt64{},
+			src:      "42",
+			expected: NullInt64{Int64: 42, Valid: true},
+		},
+		{
+			name:     "GivenNilToPointer_ThenNil",
+			dest:     func() **int { i := 1; ip := &i; return &ip }(),
+			src:      nil,
+			expected: (*int)(nil),
+		},
+		{
+			name:     "GivenInt64ToInt8_ThenConverted",
+			dest:     new(int8),
+			src:      int64(-128),
+			expected: int8(-128),
+		},
+		{
+			name:     "GivenWholeFloatToInt_ThenConverted",
+			dest:     new(int),
+			src:      float64(1e6),
+			expected: 1000000,
+		},
+		{
+			name:     "GivenIntToFloat_ThenConverted",
+			dest:     new(float32),
+			src:      int64(3),
+			expected: float32(3),
+		},
+		{
+			name:        "GivenFractionalFloatToInt_ThenErrorReturned",
+			dest:        new(int),
+			src:         1.5,
+			expectedErr: fmt.Errorf("unable to assign float64 1.5 to int without loss"),
+		},
+		{
+			name:        "GivenOverflowingInt_ThenErrorReturned",
+			dest:        new(int8),
+			src:         int64(300),
+			expectedErr: fmt.Errorf("unable to assign int64 300 to int8 without loss"),
+		},
+		{
+			name:        "GivenNegativeIntToUint_ThenErrorReturned",
+			dest:        new(uint),
+			src:         int64(-1),
+			expectedErr: fmt.Errorf("unable to assign int64 -1 to uint without loss"),
+		},
+		{
+			name:        "GivenOverflowingFloat_ThenErrorReturned",
+			dest:        new(float32),
+			src:         1e300,
+			expectedErr: fmt.Errorf("unable to assign float64 1e+300 to float32 without loss"),
+		},
+		{
+			name:        "GivenStringToStruct_ThenErrorReturned",
+			dest:        &sourceTestColour{},
+			src:         "red",
+			expectedErr: fmt.Errorf("unable to assign string to goscanql.sourceTestColour"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Act
+			err := assignValue(test.dest, test.src)
+
+			// Assert
+			assert.Equal(t, test.expectedErr, err)
+			if test.expectedErr == nil {
+				assert.Equal(t, test.expected, getRootValueOnce(test.dest))
+			}
+		})
+	}
+}
+
+// getRootValueOnce dereferences the provided pointer (p) once.
+func getRootValueOnce(p interface{}) interface{} {
+	return reflect.ValueOf(p).Elem().Interface()
+}
@@ -0,0 +1,213 @@
+package goscanql
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// valuerType is the type of the driver.Valuer interface.
+var valuerType = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+
+// columnValue represents a single column of an entity to be written, and the value to write.
+type columnValue struct {
+
+	// name is the name of the column, e.g. colour_red.
+	name string
+
+	// value is the value of the column.
+	value interface{}
+}
+
+// collectValues walks the fields of the provided entity (v) in the order that they are declared,
+// and returns the columns (and values) that should be written for it. Values and Scanners are
+// included, one-to-one relationships are flattened into the entity using the prefix scheme and
+// one-to-many relationships are skipped. An error is returned for Scanners that don't implement
+// driver.Valuer, as their values can't be written.
+//
+// Where v is nil (e.g. a nil one-to-one relationship) every column is still included, with a nil
+// value.
+func collectValues(t reflect.Type, v reflect.Value, prefix string, o *options) ([]columnValue, error) {
+	values := make([]columnValue, 0)
+	v = indirectValue(v)
+
+	for _, field := range structFields(t, o) {
+		name := buildReferenceName(prefix, field.tag.name)
+
+		var fieldValue reflect.Value
+		if v.IsValid() {
+			fieldValue = v.FieldByIndex(field.field.Index)
+		}
+
+		switch field.kind {
+		case oneToManyKind:
+			continue
+
+		case oneToOneKind:
+			children, err := collectValues(field.elemType(), fieldValue, name, o)
+			if err != nil {
+				return nil, err
+			}
+
+			values = append(values, children...)
+
+		case scannerKind:
+			if !reflect.PointerTo(field.elemType()).Implements(valuerType) {
+				return nil, fmt.Errorf("goscanql: unable to get value of field %s: %s doesn't implement driver.Valuer", name, field.elemType().String())
+			}
+
+			value, err := driverValue(fieldValue)
+			if err != nil {
+				return nil, fmt.Errorf("goscanql: unable to get value of field %s: %w", name, err)
+			}
+
+			values = append(values, columnValue{name: name, value: value})
+
+		default:
+			value, err := driverValue(fieldValue)
+			if err != nil {
+				return nil, fmt.Errorf("goscanql: unable to get value of field %s: %w", name, err)
+			}
+
+			values = append(values, columnValue{name: name, value: value})
+		}
+	}
+
+	return values, nil
+}
+
+// driverValue returns the value that should be written for the provided field value (v), which
+// is the result of Value where the field implements driver.Valuer, or the (dereferenced) value
+// otherwise. Nil is returned where the value is nil.
+func driverValue(v reflect.Value) (interface{}, error) {
+	v = indirectValue(v)
+	if !v.IsValid() {
+		return nil, nil
+	}
+
+	if v.CanAddr() {
+		v = v.Addr()
+	}
+
+	if valuer, ok := v.Interface().(driver.Valuer); ok {
+		return valuer.Value()
+	}
+
+	return indirectValue(v).Interface(), nil
+}
+
+// entityValues validates the type T and returns the columns (and values) that should be written
+// for the provided entity.
+func entityValues[T any](entity T, o *options) ([]columnValue, error) {
+	err := validateType(entity, o)
+	if err != nil {
+		return nil, err
+	}
+
+	// take the address of the entity so that its fields are addressable
+	v := reflect.New(reflect.TypeOf(entity))
+	v.Elem().Set(reflect.ValueOf(entity))
+
+	return collectValues(reflect.TypeOf(entity), v, "", o)
+}
+
+// InsertStatement builds an INSERT statement for the provided entity into the provided table,
+// returning the statement along with its arguments.
+//
+// Each value (and each Scanner, which must implement driver.Valuer) of the entity is inserted,
+// with the fields of one-to-one relationships being flattened into the entity using the same
+// prefixes that goscanql reads them with (e.g. colour_red). One-to-many relationships are not
+// inserted.
+//
+// Identifiers are quoted and placeholders numbered by the Dialect provided with WithDialect,
+// otherwise identifiers are left unquoted and ? is used for each placeholder.
+func InsertStatement[T any](table string, entity T, opts ...Option) (string, []interface{}, error) {
+	o := newOptions(opts)
+	dialect := o.getDialect()
+
+	values, err := entityValues(entity, o)
+	if err != nil {
+		return "", nil, err
+	}
+
+	columns := make([]string, len(values))
+	placeholders := make([]string, len(values))
+	args := make([]interface{}, len(values))
+
+	for i, value := range values {
+		columns[i] = dialect.Quote(value.name)
+		placeholders[i] = dialect.Placeholder(i + 1)
+		args[i] = value.value
+	}
+
+	statement := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
+		dialect.Quote(table), strings.Join(columns, ", "), strings.Join(placeholders, ", "))
+
+	return statement, args, nil
+}
+
+// UpdateStatement builds an UPDATE statement for the provided entity in the provided table,
+// returning the statement along with its arguments. The rows to update are identified by the
+// columns provided with WithUpdateKeys (e.g. "id"), which must be provided, and every other
+// column (as described by InsertStatement) is updated.
+func UpdateStatement[T any](table string, entity T, opts ...Option) (string, []interface{}, error) {
+	o := newOptions(opts)
+	dialect := o.getDialect()
+	keyTags := o.updateKeys
+
+	if len(keyTags) == 0 {
+		return "", nil, fmt.Errorf("goscanql: at least one key must be provided to update %s", table)
+	}
+
+	values, err := entityValues(entity, o)
+	if err != nil {
+		return "", nil, err
+	}
+
+	keys := make(map[string]interface{}, len(keyTags))
+	sets := make([]string, 0, len(values))
+	args := make([]interface{}, 0, len(values))
+
+	for _, value := range values {
+		if containsString(keyTags, value.name) {
+			keys[value.name] = value.value
+			continue
+		}
+
+		args = append(args, value.value)
+		sets = append(sets, fmt.Sprintf("%s = %s", dialect.Quote(value.name), dialect.Placeholder(len(args))))
+	}
+
+	if len(sets) == 0 {
+		return "", nil, fmt.Errorf("goscanql: no columns other than the keys to update in %s", table)
+	}
+
+	conditions := make([]string, len(keyTags))
+
+	for i, key := range keyTags {
+		value, ok := keys[key]
+		if !ok {
+			return "", nil, fmt.Errorf("goscanql: key %s is not a column of %T", key, entity)
+		}
+
+		args = append(args, value)
+		conditions[i] = fmt.Sprintf("%s = %s", dialect.Quote(key), dialect.Placeholder(len(args)))
+	}
+
+	statement := fmt.Sprintf("UPDATE %s SET %s WHERE %s",
+		dialect.Quote(table), strings.Join(sets, ", "), strings.Join(conditions, " AND "))
+
+	return statement, args, nil
+}
+
+// containsString returns true if the provided slice (s) contains the provided string (str).
+func containsString(s []string, str string) bool {
+	for _, candidate := range s {
+		if candidate == str {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,157 @@
+package goscanql
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type statementTestColour struct {
+	Red   int `sql:"red"`
+	Green int `sql:"green"`
+}
+
+type statementTestPet struct {
+	Name string `sql:"name"`
+}
+
+type statementTestAccount struct {
+	ID          int                  `sql:"id"`
+	Email       *string              `sql:"email"`
+	DateOfBirth time.Time            `sql:"date_of_birth"`
+	Nickname    NullString           `sql:"nickname"`
+	Colour      *statementTestColour `sql:"colour"`
+	Pets        []statementTestPet   `sql:"pets"`
+	Ignored     string
+}
+
+func TestInsertStatement(t *testing.T) {
+	email := "sterling.archer@isis.com"
+	dateOfBirth := time.Date(1978, 12, 30, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name              string
+		input             statementTestAccount
+		opts              []Option
+		expectedStatement string
+		expectedArgs      []interface{}
+	}{
+		{
+			name: "GivenNoDialect_ThenUnquotedStatement",
+			input: statementTestAccount{
+				ID:          1,
+				Email:       &email,
+				DateOfBirth: dateOfBirth,
+				Nickname:    NullString{String: "Duchess", Valid: true},
+				Colour:      &statementTestColour{Red: 255, Green: 128},
+				Pets:        []statementTestPet{{Name: "Babou"}},
+				Ignored:     "ignored",
+			},
+			expectedStatement: "INSERT INTO account (id, email, date_of_birth, nickname, colour_red, colour_green) VALUES (?, ?, ?, ?, ?, ?)",
+			expectedArgs:      []interface{}{1, email, dateOfBirth, "Duchess", 255, 128},
+		},
+		{
+			name: "GivenPostgresAndNils_ThenQuotedStatementWithNilArgs",
+			input: statementTestAccount{
+				ID:          2,
+				DateOfBirth: dateOfBirth,
+			},
+			opts:              []Option{WithDialect(Postgres)},
+			expectedStatement: `INSERT INTO "account" ("id", "email", "date_of_birth", "nickname", "colour_red", "colour_green") VALUES ($1, $2, $3, $4, $5, $6)`,
+			expectedArgs:      []interface{}{2, nil, dateOfBirth, nil, nil, nil},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Act
+			statement, args, err := InsertStatement("account", test.input, test.opts...)
+
+			// Assert
+			assert.Nil(t, err)
+			assert.Equal(t, test.expectedStatement, statement)
+			assert.Equal(t, test.expectedArgs, args)
+		})
+	}
+}
+
+func TestInsertStatement_ScannerWithoutValuer(t *testing.T) {
+	type account struct {
+		ID              int            `sql:"id"`
+		Characteristics exampleScanner `sql:"characteristics"`
+	}
+
+	// Act
+	statement, args, err := InsertStatement("account", account{ID: 1})
+
+	// Assert
+	assert.Equal(t, fmt.Errorf("goscanql: unable to get value of field characteristics: goscanql.exampleScanner doesn't implement driver.Valuer"), err)
+	assert.Equal(t, "", statement)
+	assert.Nil(t, args)
+}
+
+func TestUpdateStatement(t *testing.T) {
+	input := &statementTestAccount{
+		ID:     1,
+		Colour: &statementTestColour{Red: 255, Green: 128},
+	}
+
+	tests := []struct {
+		name              string
+		keys              []string
+		opts              []Option
+		expectedStatement string
+		expectedArgs      []interface{}
+		expectedErr       error
+	}{
+		{
+			name:              "GivenKey_ThenKeyUsedInCondition",
+			keys:              []string{"id"},
+			expectedStatement: "UPDATE account SET email = ?, date_of_birth = ?, nickname = ?, colour_red = ?, colour_green = ? WHERE id = ?",
+			expectedArgs:      []interface{}{nil, time.Time{}, nil, 255, 128, 1},
+		},
+		{
+			name:              "GivenMultipleKeysAndPostgres_ThenKeysUsedInCondition",
+			keys:              []string{"id", "colour_red"},
+			opts:              []Option{WithDialect(Postgres)},
+			expectedStatement: `UPDATE "account" SET "email" = $1, "date_of_birth" = $2, "nickname" = $3, "colour_green" = $4 WHERE "id" = $5 AND "colour_red" = $6`,
+			expectedArgs:      []interface{}{nil, time.Time{}, nil, 128, 1, 255},
+		},
+		{
+			name:        "GivenNoKeys_ThenErrorReturned",
+			keys:        nil,
+			expectedErr: fmt.Errorf("goscanql: at least one key must be provided to update account"),
+		},
+		{
+			name:        "GivenUnknownKey_ThenErrorReturned",
+			keys:        []string{"pets"},
+			expectedErr: fmt.Errorf("goscanql: key pets is not a column of *goscanql.statementTestAccount"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Act
+			opts := append([]Option{WithUpdateKeys(test.keys...)}, test.opts...)
+
+			statement, args, err := UpdateStatement("account", input, opts...)
+
+			// Assert
+			assert.Equal(t, test.expectedErr, err)
+			assert.Equal(t, test.expectedStatement, statement)
+			assert.Equal(t, test.expectedArgs, args)
+		})
+	}
+}
+
+func TestUpdateStatement_DefaultOptions(t *testing.T) {
+	// Act
+	statement, args, err := UpdateStatement("pet", statementTestPet{Name: "Babou"}, WithUpdateKeys("name"))
+
+	// Assert
+	assert.Equal(t, fmt.Errorf("goscanql: no columns other than the keys to update in pet"), err)
+	assert.Equal(t, "", statement)
+	assert.Nil(t, args)
+}
@@ -0,0 +1,93 @@
+package goscanql
+
+import (
+	"reflect"
+)
+
+// fieldKind represents the way in which goscanql treats a field of a struct.
+type fieldKind int
+
+const (
+	// valueKind represents a field that is scanned as a single value, e.g. an int, string or
+	// time.Time.
+	valueKind fieldKind = iota
+
+	// scannerKind represents a field that implements Scanner.
+	scannerKind
+
+	// oneToOneKind represents a nested struct, which is treated as a one-to-one relationship.
+	oneToOneKind
+
+	// oneToManyKind represents a slice, which is treated as a one-to-many relationship.
+	oneToManyKind
+)
+
+// kindOf returns the fieldKind of a field of the provided type (t). Types with a converter (as
+// provided by o, which may be nil) are treated as single values.
+func kindOf(t reflect.Type, o *options) fieldKind {
+	root := getPointerRootType(t)
+
+	switch {
+	case o.hasConverter(root):
+		return valueKind
+	case implementsScanner(root) || implementsScanner(reflect.PointerTo(root)):
+		return scannerKind
+	case root == timeType:
+		return valueKind
+	case root.Kind() == reflect.Struct:
+		return oneToOneKind
+	case root.Kind() == reflect.Slice:
+		return oneToManyKind
+	}
+
+	return valueKind
+}
+
+// structField represents a single field of a struct that is mapped by goscanql.
+type structField struct {
+
+	// field is the reflected field of the struct.
+	field reflect.StructField
+
+	// tag is the parsed sql tag of the field (with the name resolved).
+	tag tag
+
+	// kind is the way in which goscanql treats the field.
+	kind fieldKind
+}
+
+// elemType returns the type of the struct (or value) that the field holds, stripping away any
+// pointers and, for one-to-many relationships, the slice.
+func (sf structField) elemType() reflect.Type {
+	t := getPointerRootType(sf.field.Type)
+
+	// slices are one-dimensional (other than those of Scanners and types with a converter, which
+	// are single values), so only the one slice is stripped away
+	if sf.kind == oneToManyKind {
+		t = getPointerRootType(t.Elem())
+	}
+
+	return t
+}
+
+// structFields returns each of the fields of the provided struct type (t) that are mapped by
+// goscanql, in the order that they are declared.
+func structFields(t reflect.Type, o *options) []structField {
+	t = getPointerRootType(t)
+	result := make([]structField, 0, t.NumField())
+
+	for i := 0; i < t.NumField(); i++ {
+		fieldTag, ok := o.fieldTag(t, i)
+		if !ok {
+			continue
+		}
+
+		result = append(result, structField{
+			field: t.Field(i),
+			tag:   fieldTag,
+			kind:  kindOf(t.Field(i).Type, o),
+		})
+	}
+
+	return result
+}
@@ -0,0 +1,168 @@
+package goscanql
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const (
+	// multisetTagOption is the tag option used to mark a one-to-many relationship as one where
+	// duplicate children should be preserved, e.g. `sql:"items,multiset"`.
+	multisetTagOption = "multiset"
+
+	// keyTagOption is the tag option that a FieldMapping marks a field as one that identifies the
+	// entity with (it is rejected in sql tags). Where a struct has key fields, only they are used to
+	// determine whether two rows represent the same entity.
+	keyTagOption = "key"
+
+	// notNullTagOption is the tag option used to mark a field as one that must not be scanned
+	// from a null column, e.g. `sql:"age,notnull"`.
+	notNullTagOption = "notnull"
+
+	// defaultTagOption is the tag option used to provide the value of a field where its column is
+	// null, e.g. `sql:"status,default=active"`.
+	defaultTagOption = "default"
+
+	// extraTagOption is the tag option used to mark a map[string]interface{} field as the one that
+	// collects the columns of its entity that aren't mapped to any other field, e.g. `sql:",extra"`.
+	extraTagOption = "extra"
+)
+
+// tag represents the parsed value of an sql tag, which takes the form of a name followed by
+// any number of comma separated options (each of which may have a value), e.g.
+//
+//	`sql:"pets,multiset"`
+type tag struct {
+
+	// name is the name of the field (which forms part of the column name).
+	name string
+
+	// options holds each of the options provided after the name, mapped to their values (or
+	// an empty string where an option has no value).
+	options map[string]string
+}
+
+// parseTag parses the raw value of an sql tag into a tag. Options are separated by the commas
+// that aren't within parentheses or single quotes (see splitTag), and a value that is wholly
+// single quoted is unquoted, e.g. `sql:"status,default='a,b'"`.
+func parseTag(raw string) tag {
+	parts, _ := splitTag(raw)
+
+	t := tag{
+		name:    parts[0],
+		options: make(map[string]string),
+	}
+
+	for _, part := range parts[1:] {
+		key, value, _ := strings.Cut(part, "=")
+		t.options[strings.TrimSpace(key)] = unquoteTagValue(value)
+	}
+
+	return t
+}
+
+// splitTag splits the raw value of an sql tag on each of the commas that aren't within
+// parentheses or single quotes (so that a join condition such as "a.id IN (1,2)" is kept whole).
+// An error is returned (along with the parts as far as they could be split) where the raw value
+// has an unterminated quote or unbalanced parentheses.
+func splitTag(raw string) ([]string, error) {
+	parts := make([]string, 0, 1)
+	depth := 0
+	quoted := false
+	start := 0
+
+	for i := 0; i < len(raw); i++ {
+		switch raw[i] {
+		case '\'':
+			// a quote within a quoted value is escaped by doubling it, which toggles twice
+			quoted = !quoted
+		case '(':
+			if !quoted {
+				depth++
+			}
+		case ')':
+			if !quoted {
+				depth--
+				if depth < 0 {
+					return append(parts, raw[start:]), fmt.Errorf("unbalanced parentheses in tag %q", raw)
+				}
+			}
+		case ',':
+			if !quoted && depth == 0 {
+				parts = append(parts, raw[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	parts = append(parts, raw[start:])
+
+	if quoted {
+		return parts, fmt.Errorf("unterminated quote in tag %q", raw)
+	}
+
+	if depth != 0 {
+		return parts, fmt.Errorf("unbalanced parentheses in tag %q", raw)
+	}
+
+	return parts, nil
+}
+
+// unquoteTagValue returns the provided tag option value with its quotes removed (and any doubled
+// quotes within it unescaped) where it is wholly single quoted, otherwise the value is returned
+// as it is.
+func unquoteTagValue(value string) string {
+	if len(value) < 2 || value[0] != '\'' || value[len(value)-1] != '\'' {
+		return value
+	}
+
+	inner := value[1 : len(value)-1]
+
+	// a lone quote within the value means that it isn't a single quoted string (e.g. 'a' || 'b')
+	if strings.Contains(strings.ReplaceAll(inner, "''", ""), "'") {
+		return value
+	}
+
+	return strings.ReplaceAll(inner, "''", "'")
+}
+
+// validateTags ensures that the sql tag of each of the fields of the provided type (t) (or its
+// equivalent in the Mapping of o) can be split into its options, and that sql tags don't have the
+// key option (which is only read from a Mapping).
+func validateTags(t reflect.Type, o *options) error {
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	mapped := false
+	if o != nil && o.mapping != nil {
+		_, mapped = o.mapping.typeMapping(t)
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		raw, _ := o.rawFieldTag(t, i)
+
+		if _, err := splitTag(raw); err != nil {
+			return fmt.Errorf("goscanql: invalid tag of field %s.%s: %w", t.String(), t.Field(i).Name, err)
+		}
+
+		if !mapped && parseTag(raw).has(keyTagOption) {
+			return fmt.Errorf("goscanql: invalid tag of field %s.%s: the key option is only supported by a Mapping", t.String(), t.Field(i).Name)
+		}
+	}
+
+	return nil
+}
+
+// has returns true if the tag has the provided option.
+func (t tag) has(option string) bool {
+	_, ok := t.options[option]
+	return ok
+}
+
+// get returns the value of the provided option, and whether the tag has the option.
+func (t tag) get(option string) (string, bool) {
+	value, ok := t.options[option]
+	return value, ok
+}
@@ -0,0 +1,193 @@
+package goscanql
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTag(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected tag
+	}{
+		{
+			name:  "NameOnly",
+			input: "pets",
+			expected: tag{
+				name:    "pets",
+				options: map[string]string{},
+			},
+		},
+		{
+			name:  "NameWithOption",
+			input: "items,multiset",
+			expected: tag{
+				name: "items",
+				options: map[string]string{
+					"multiset": "",
+				},
+			},
+		},
+		{
+			name:  "NameWithValuedOptions",
+			input: "pets,multiset,key=a=b",
+			expected: tag{
+				name: "pets",
+				options: map[string]string{
+					"multiset": "",
+					"key":      "a=b",
+				},
+			},
+		},
+		{
+			name:  "OptionsOnly",
+			input: ",multiset",
+			expected: tag{
+				name: "",
+				options: map[string]string{
+					"multiset": "",
+				},
+			},
+		},
+		{
+			name:  "CommaWithinParentheses",
+			input: "pets,table=pet,on=pet.kind IN (1,2),multiset",
+			expected: tag{
+				name: "pets",
+				options: map[string]string{
+					"table":    "pet",
+					"on":       "pet.kind IN (1,2)",
+					"multiset": "",
+				},
+			},
+		},
+		{
+			name:  "QuotedValue",
+			input: "status,default='active,locked',notnull",
+			expected: tag{
+				name: "status",
+				options: map[string]string{
+					"default": "active,locked",
+					"notnull": "",
+				},
+			},
+		},
+		{
+			name:  "QuotedValueWithEscapedQuote",
+			input: "note,default='it''s, fine'",
+			expected: tag{
+				name: "note",
+				options: map[string]string{
+					"default": "it's, fine",
+				},
+			},
+		},
+		{
+			name:  "PartlyQuotedValue",
+			input: "colour,table=colour,on=colour.name = 'red, green'",
+			expected: tag{
+				name: "colour",
+				options: map[string]string{
+					"table": "colour",
+					"on":    "colour.name = 'red, green'",
+				},
+			},
+		},
+		{
+			name:  "Empty",
+			input: "",
+			expected: tag{
+				name:    "",
+				options: map[string]string{},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Act
+			result := parseTag(test.input)
+
+			// Assert
+			assert.Equal(t, test.expected, result)
+		})
+	}
+}
+
+func TestSplitTag(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expected    []string
+		expectedErr error
+	}{
+		{
+			name:     "Valid",
+			input:    "pets,on=pet.kind IN ('a,b', 'c'),multiset",
+			expected: []string{"pets", "on=pet.kind IN ('a,b', 'c')", "multiset"},
+		},
+		{
+			name:        "UnterminatedQuote",
+			input:       "status,default='active",
+			expected:    []string{"status", "default='active"},
+			expectedErr: fmt.Errorf("unterminated quote in tag \"status,default='active\""),
+		},
+		{
+			name:        "UnclosedParenthesis",
+			input:       "pets,on=pet.kind IN (1,2",
+			expected:    []string{"pets", "on=pet.kind IN (1,2"},
+			expectedErr: fmt.Errorf("unbalanced parentheses in tag \"pets,on=pet.kind IN (1,2\""),
+		},
+		{
+			name:        "UnopenedParenthesis",
+			input:       "pets,on=pet.kind IN 1,2)",
+			expected:    []string{"pets", "on=pet.kind IN 1", "2)"},
+			expectedErr: fmt.Errorf("unbalanced parentheses in tag \"pets,on=pet.kind IN 1,2)\""),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Act
+			result, err := splitTag(test.input)
+
+			// Assert
+			assert.Equal(t, test.expected, result)
+			assert.Equal(t, test.expectedErr, err)
+		})
+	}
+}
+
+func TestValidateTags(t *testing.T) {
+	type valid struct {
+		Status string `sql:"status,default='a,b'"`
+	}
+
+	type invalid struct {
+		Status string `sql:"status,default='a,b"`
+	}
+
+	type keyed struct {
+		ID int `sql:"id,key"`
+	}
+
+	mapped := newOptions([]Option{WithMapping(NewMapping().Map(keyed{}, TypeMapping{
+		"ID": {Column: "id", Key: true},
+	}))})
+
+	// Act
+	validErr := validateTags(reflect.TypeOf(valid{}), nil)
+	invalidErr := validateTags(reflect.TypeOf(invalid{}), nil)
+	keyedErr := validateTags(reflect.TypeOf(keyed{}), nil)
+	mappedErr := validateTags(reflect.TypeOf(keyed{}), mapped)
+
+	// Assert
+	assert.Nil(t, validErr)
+	assert.EqualError(t, invalidErr, "goscanql: invalid tag of field goscanql.invalid.Status: unterminated quote in tag \"status,default='a,b\"")
+	assert.EqualError(t, keyedErr, "goscanql: invalid tag of field goscanql.keyed.ID: the key option is only supported by a Mapping")
+	assert.Nil(t, mappedErr)
+}
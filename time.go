@@ -0,0 +1,184 @@
+package goscanql
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// WithTimeLocation returns an Option that will convert the value of every time field (including
+// NullTimes) to the provided location once it has been scanned, e.g. time.UTC, so that times
+// read from databases (or connections) in differing zones are consistent.
+func WithTimeLocation(loc *time.Location) Option {
+	return func(o *options) {
+		o.timeLocation = loc
+	}
+}
+
+// WithTimeLayouts returns an Option that will parse string (and []byte) values of time columns
+// with the provided layouts (in order, as accepted by time.Parse), e.g. for SQLite, or MySQL
+// without parseTime. Values without a zone are parsed in the location of WithTimeLocation (or UTC
+// where it isn't provided).
+//
+// Where time columns are expected to be strings but no layouts are provided (i.e. with
+// WithTimeLocation or WithUnixTime alone), RFC 3339, "2006-01-02 15:04:05" and "2006-01-02" are
+// accepted.
+func WithTimeLayouts(layouts ...string) Option {
+	return func(o *options) {
+		o.timeLayouts = layouts
+	}
+}
+
+// WithUnixTime returns an Option that will read integer (and float) values of time columns as the
+// number of the provided units (e.g. time.Second or time.Millisecond) since the unix epoch.
+func WithUnixTime(unit time.Duration) Option {
+	return func(o *options) {
+		o.unixTime = unit
+	}
+}
+
+// timeConverter returns the converter of time.Time that is described by the time options of o,
+// if any of them have been provided.
+func (o *options) timeConverter() (converter, bool) {
+	if o == nil || o.timeLocation == nil && o.timeLayouts == nil && o.unixTime <= 0 {
+		return nil, false
+	}
+
+	return func(src interface{}) (reflect.Value, error) {
+		t, err := o.convertTime(src)
+		return reflect.ValueOf(t), err
+	}, true
+}
+
+// convertTime converts the provided value of a time column (src) to a time.Time, as described by
+// the time options of o (which must not be nil).
+func (o *options) convertTime(src interface{}) (time.Time, error) {
+	var t time.Time
+
+	switch s := src.(type) {
+	case nil:
+		return time.Time{}, nil
+	case time.Time:
+		t = s
+	case []byte:
+		return o.parseTime(string(s))
+	case string:
+		return o.parseTime(s)
+	case int64:
+		if o.unixTime <= 0 {
+			return time.Time{}, fmt.Errorf("unable to convert %d to a time (unix times aren't enabled)", s)
+		}
+
+		t = unixTime(s, o.unixTime)
+	case float64:
+		if o.unixTime <= 0 {
+			return time.Time{}, fmt.Errorf("unable to convert %g to a time (unix times aren't enabled)", s)
+		}
+
+		t = time.Unix(0, 0).Add(time.Duration(s * float64(o.unixTime)))
+	default:
+		return time.Time{}, fmt.Errorf("unable to convert %T to a time", src)
+	}
+
+	if o.timeLocation != nil {
+		t = t.In(o.timeLocation)
+	}
+
+	return t, nil
+}
+
+// parseTime parses the provided string (s) with the layouts of o, falling back to reading it as
+// a unix time where unix times are enabled and s is an integer.
+func (o *options) parseTime(s string) (time.Time, error) {
+	layouts := o.timeLayouts
+	if layouts == nil {
+		layouts = timeLayouts
+	}
+
+	loc := o.timeLocation
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	for _, layout := range layouts {
+		t, err := time.ParseInLocation(layout, s, loc)
+		if err == nil {
+			return t.In(loc), nil
+		}
+	}
+
+	if o.unixTime > 0 {
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return unixTime(i, o.unixTime).In(loc), nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unable to parse %q as a time", s)
+}
+
+// unixTime returns the time that is the provided number (n) of units since the unix epoch.
+func unixTime(n int64, unit time.Duration) time.Time {
+	if unit >= time.Second {
+		return time.Unix(n*int64(unit/time.Second), 0)
+	}
+
+	perSecond := int64(time.Second / unit)
+	return time.Unix(n/perSecond, n%perSecond*int64(unit))
+}
+
+// timeID returns the identity of the provided time, which is the instant that it represents
+// (regardless of its location), so that equal times read in differing zones are identified alike.
+func timeID(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// timeOf returns the time that the provided reference (e.g. a *time.Time, or a **time.Time for a
+// pointer field) holds, and whether it holds one (which it doesn't where any pointer is nil).
+func timeOf(reference interface{}) (time.Time, bool) {
+	if t, ok := reference.(*time.Time); ok {
+		return *t, true
+	}
+
+	v := reflect.ValueOf(reference)
+
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return time.Time{}, false
+		}
+
+		v = v.Elem()
+	}
+
+	if !v.IsValid() || v.Type() != timeType {
+		return time.Time{}, false
+	}
+
+	return v.Interface().(time.Time), true
+}
+
+// nullTimeScanner is the destination of a column whose field is a NullTime where time options
+// have been provided, converting each of its values before they are scanned by the NullTime.
+type nullTimeScanner struct {
+
+	// dest is the NullTime that the converted values are scanned into.
+	dest sql.Scanner
+
+	// convert is the converter described by the time options.
+	convert converter
+}
+
+// Scan converts the provided value (src) to a time.Time, and scans it into the NullTime.
+func (s *nullTimeScanner) Scan(src interface{}) error {
+	if src == nil {
+		return s.dest.Scan(nil)
+	}
+
+	v, err := s.convert(src)
+	if err != nil {
+		return err
+	}
+
+	return s.dest.Scan(v.Interface())
+}
@@ -0,0 +1,215 @@
+package goscanql
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RowsToStructsWithTimeOptions(t *testing.T) {
+	type event struct {
+		Name string      `sql:"name"`
+		At   time.Time   `sql:"at"`
+		Ends *time.Time  `sql:"ends"`
+		Seen NullTime    `sql:"seen"`
+		Logs []time.Time `sql:"log"`
+	}
+
+	london, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		panic(err)
+	}
+
+	// Arrange
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		panic(err)
+	}
+
+	inputRows := sqlmock.NewRows([]string{"name", "at", "ends", "seen", "log"})
+
+	for _, row := range [][]driver.Value{
+		{"launch", "2024-06-01 09:00", int64(1717236000), []byte("2024-06-01 10:30"), int64(1717232400)},
+		{"launch", "2024-06-01 09:00", int64(1717236000), []byte("2024-06-01 10:30"), "1717236000"},
+		{"review", time.Date(2024, 6, 2, 8, 0, 0, 0, time.UTC), nil, nil, nil},
+	} {
+		inputRows.AddRow(row...)
+	}
+
+	mock.ExpectQuery("SELECT").WillReturnRows(inputRows)
+
+	rows, err := db.Query("SELECT")
+	if err != nil {
+		panic(err)
+	}
+
+	ends := time.Date(2024, 6, 1, 11, 0, 0, 0, london)
+
+	expected := []event{
+		{
+			Name: "launch",
+			At:   time.Date(2024, 6, 1, 9, 0, 0, 0, london),
+			Ends: &ends,
+			Seen: NullTime{Time: time.Date(2024, 6, 1, 10, 30, 0, 0, london), Valid: true},
+			Logs: []time.Time{
+				time.Date(2024, 6, 1, 10, 0, 0, 0, london),
+				time.Date(2024, 6, 1, 11, 0, 0, 0, london),
+			},
+		},
+		{
+			Name: "review",
+			At:   time.Date(2024, 6, 2, 9, 0, 0, 0, london),
+		},
+	}
+
+	// Act
+	result, err := RowsToStructs[event](rows, WithTimeLocation(london), WithTimeLayouts("2006-01-02 15:04"), WithUnixTime(time.Second))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, expected, result)
+}
+
+func Test_RowsToStructsWithTimesInDifferentZones(t *testing.T) {
+	type event struct {
+		At   time.Time `sql:"at"`
+		Tags []string  `sql:"tag"`
+	}
+
+	paris := time.FixedZone("CEST", 2*60*60)
+	at := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+
+	// Arrange
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		panic(err)
+	}
+
+	inputRows := sqlmock.NewRows([]string{"at", "tag"}).
+		AddRow(at, "a").
+		AddRow(at.In(paris), "b")
+
+	mock.ExpectQuery("SELECT").WillReturnRows(inputRows)
+
+	rows, err := db.Query("SELECT")
+	if err != nil {
+		panic(err)
+	}
+
+	// Act
+	result, err := RowsToStructs[event](rows)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []event{{At: at, Tags: []string{"a", "b"}}}, result)
+}
+
+func Test_RowsToStructsWithPointerTimesInDifferentZones(t *testing.T) {
+	type event struct {
+		At   *time.Time `sql:"at"`
+		Tags []string   `sql:"tag"`
+	}
+
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		panic(err)
+	}
+
+	at := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+
+	// Arrange
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		panic(err)
+	}
+
+	inputRows := sqlmock.NewRows([]string{"at", "tag"}).
+		AddRow(at, "a").
+		AddRow(at.In(newYork), "b").
+		AddRow(at.Add(time.Hour), "c")
+
+	mock.ExpectQuery("SELECT").WillReturnRows(inputRows)
+
+	rows, err := db.Query("SELECT")
+	if err != nil {
+		panic(err)
+	}
+
+	// Act
+	result, err := RowsToStructs[event](rows)
+
+	// Assert
+	assert.Nil(t, err)
+	later := at.Add(time.Hour)
+	assert.Equal(t, []event{{At: &at, Tags: []string{"a", "b"}}, {At: &later, Tags: []string{"c"}}}, result)
+}
+
+func Test_RowsToStructsWithTimeOptionsError(t *testing.T) {
+	type event struct {
+		At time.Time `sql:"at"`
+	}
+
+	for name, test := range map[string]struct {
+		value    driver.Value
+		opts     []Option
+		expected string
+	}{
+		"unknown layout": {
+			value:    "01/06/2024",
+			opts:     []Option{WithTimeLayouts(time.DateOnly)},
+			expected: `unable to parse "01/06/2024" as a time`,
+		},
+		"unix times not enabled": {
+			value:    int64(1717232400),
+			opts:     []Option{WithTimeLocation(time.UTC)},
+			expected: "unable to convert 1717232400 to a time (unix times aren't enabled)",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				panic(err)
+			}
+
+			mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"at"}).AddRow(test.value))
+
+			rows, err := db.Query("SELECT")
+			if err != nil {
+				panic(err)
+			}
+
+			// Act
+			_, err = RowsToStructs[event](rows, test.opts...)
+
+			// Assert
+			assert.NotNil(t, err)
+			assert.True(t, strings.HasSuffix(err.Error(), test.expected), err.Error())
+		})
+	}
+}
+
+func TestUnixTime(t *testing.T) {
+	for name, test := range map[string]struct {
+		n        int64
+		unit     time.Duration
+		expected time.Time
+	}{
+		"seconds":      {n: 1717232400, unit: time.Second, expected: time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)},
+		"milliseconds": {n: 1717232400123, unit: time.Millisecond, expected: time.Date(2024, 6, 1, 9, 0, 0, 123000000, time.UTC)},
+		"negative":     {n: -1500, unit: time.Millisecond, expected: time.Date(1969, 12, 31, 23, 59, 58, 500000000, time.UTC)},
+		"minutes":      {n: 2, unit: time.Minute, expected: time.Date(1970, 1, 1, 0, 2, 0, 0, time.UTC)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			result := unixTime(test.n, test.unit)
+
+			// Assert
+			assert.True(t, test.expected.Equal(result), result.String())
+		})
+	}
+}
@@ -196,8 +196,9 @@ func isNotCustomInterface(t reflect.Type) error {
 }
 
 // validateType analyses the provided input type and ensures that it will is valid based on
-// goscanql's input rules (including no cyclic structs).
-func validateType(it interface{}) error {
+// goscanql's input rules (including no cyclic structs). The provided options (o) determine
+// which fields are evaluated, and may be nil.
+func validateType(it interface{}, o *options) error {
 	t := reflect.TypeOf(it)
 
 	// run checks on input type
@@ -211,14 +212,52 @@ func validateType(it interface{}) error {
 	// assert no cyclic-structs
 	// NOTE: this check must happen before the fieldValidators check as if there is a cyclic
 	// struct, the fieldValidators check will end up in infinite recursion
-	err := verifyNoCycles(t)
+	err := verifyNoCycles(t, o)
 	if err != nil {
 		return err
 	}
 
 	// run checks on all child-types of input type (and additional checks on input type)
 	for _, validator := range fieldValidators {
-		err := traverseType(t, validator)
+		err := traverseType(t, validator, o)
+		if err != nil {
+			return err
+		}
+	}
+
+	// check that the tags of fields can be split into their options
+	err = traverseType(t, func(t reflect.Type) error { return validateTags(t, o) }, o)
+	if err != nil {
+		return err
+	}
+
+	// check that the default values of fields (if any) can be scanned into the fields
+	err = traverseType(t, func(t reflect.Type) error { return validateDefaults(t, o) }, o)
+	if err != nil {
+		return err
+	}
+
+	// check that the extra fields (if any) can collect the columns that aren't mapped
+	err = traverseType(t, func(t reflect.Type) error { return validateExtras(t, o) }, o)
+	if err != nil {
+		return err
+	}
+
+	// check that columns matched regardless of case can still be told apart
+	err = validateColumnCases(t, o)
+	if err != nil {
+		return err
+	}
+
+	// check that the sort keys (if any) name fields of the entities that they sort
+	err = validateSortKeys(t, o)
+	if err != nil {
+		return err
+	}
+
+	// check the mapping (if any) against each of the types that it describes
+	if o != nil && o.mapping != nil {
+		err := traverseType(t, func(t reflect.Type) error { return o.mapping.validate(t, o) }, o)
 		if err != nil {
 			return err
 		}
@@ -240,7 +279,8 @@ func getPointerRootType(t reflect.Type) reflect.Type {
 }
 
 // getSliceRootType takes a reflect.Type (t) as input and returns the first non-slice
-// type.
+// type. Types with a converter (see the options, o) are single values even where they are
+// slices (e.g. net.IP), so are returned as they are.
 //
 // NOTE: pointers to slices are treated as slices, but slices to pointers of
 // non-slices, are left as pointers.
@@ -248,15 +288,15 @@ func getPointerRootType(t reflect.Type) reflect.Type {
 // For example, **[]*[]string would return string, but **[]*[]*string would return
 // *string as the type (leaving the pointer on the string type even though the
 // pointers to slices have been treated as slices).
-func getSliceRootType(t reflect.Type) reflect.Type {
+func getSliceRootType(t reflect.Type, o *options) reflect.Type {
 	raw := getPointerRootType(t)
 
-	if raw.Kind() != reflect.Slice {
+	if raw.Kind() != reflect.Slice || o.hasConverter(raw) {
 		return t
 	}
 
 	// pass forward slice type, e.g. []*Example has a slice type of *Example
-	return getSliceRootType(raw.Elem())
+	return getSliceRootType(raw.Elem(), o)
 }
 
 // verifyNoCycles takes a reflect.Type (t) and analyses it for cycles (where a struct
@@ -264,14 +304,14 @@ func getSliceRootType(t reflect.Type) reflect.Type {
 //
 // NOTE: this function assumes that t is a struct type, any other type will result in
 // a panic.
-func verifyNoCycles(t reflect.Type) error {
+func verifyNoCycles(t reflect.Type, o *options) error {
 	t = getPointerRootType(t)
 
 	if t.Kind() != reflect.Struct {
 		return nil
 	}
 
-	cyclic := hasCycle(t, map[reflect.Type]interface{}{})
+	cyclic := hasCycle(t, map[reflect.Type]interface{}{}, o)
 	if !cyclic {
 		return nil
 	}
@@ -285,19 +325,19 @@ func verifyNoCycles(t reflect.Type) error {
 //
 // NOTE: this function assumes that t is a struct type, any other type will result in
 // a panic.
-func hasCycle(t reflect.Type, m map[reflect.Type]interface{}) bool {
+func hasCycle(t reflect.Type, m map[reflect.Type]interface{}, o *options) bool {
 	m[t] = struct{}{}
 	defer delete(m, t)
 
 	for i := 0; i < t.NumField(); i++ {
-		if !isGoscanqlField(t.Field(i)) {
+		if !isGoscanqlField(t, i, o) {
 			continue
 		}
 
-		fieldType := getSliceRootType(t.Field(i).Type) // strip away slices
-		fieldType = getPointerRootType(fieldType)      // strip away pointers
+		fieldType := getSliceRootType(t.Field(i).Type, o) // strip away slices
+		fieldType = getPointerRootType(fieldType)         // strip away pointers
 
-		if fieldType.Kind() != reflect.Struct {
+		if fieldType.Kind() != reflect.Struct || o.hasConverter(fieldType) {
 			continue
 		}
 
@@ -306,7 +346,7 @@ func hasCycle(t reflect.Type, m map[reflect.Type]interface{}) bool {
 			return true
 		}
 
-		cyclic := hasCycle(fieldType, m)
+		cyclic := hasCycle(fieldType, m, o)
 		if cyclic {
 			return true
 		}
@@ -315,11 +355,12 @@ func hasCycle(t reflect.Type, m map[reflect.Type]interface{}) bool {
 	return false
 }
 
-// isGoscanqlField takes a reflect.Field (f) and evaluates whether it is a field
-// designated for goscanql or not (meaning the parent struct has it tagged with
-// `sql:"tag_name"`). If so, true is returned, otherwise false.
-func isGoscanqlField(f reflect.StructField) bool {
-	_, b := f.Tag.Lookup(scanqlTag)
+// isGoscanqlField takes the i'th field of a struct type (t) and evaluates whether it is a
+// field designated for goscanql or not (meaning the parent struct has it tagged with
+// `sql:"tag_name"`, it is described by the Mapping of o, or it is an exported field and o
+// has a NameMapper). If so, true is returned, otherwise false.
+func isGoscanqlField(t reflect.Type, i int, o *options) bool {
+	_, b := o.fieldName(t, i)
 	return b
 }
 
@@ -329,9 +370,20 @@ func isGoscanqlField(f reflect.StructField) bool {
 //
 // If a non-struct type is provided, the function will be run on the provided type
 // and return immediately (as there are now more fields to traverse).
-func traverseType(t reflect.Type, f func(t reflect.Type) error) error {
+func traverseType(t reflect.Type, f func(t reflect.Type) error, o *options) error {
 	t = getPointerRootType(t)
 
+	// types with a converter are single values, so aren't checked (or traversed)
+	if o.hasConverter(t) {
+		return nil
+	}
+
+	// slices of types with a converter are slices of single values (even where the type is itself
+	// a slice or an array, e.g. []net.IP), so aren't checked either
+	if t.Kind() == reflect.Slice && o.hasConverter(t.Elem()) {
+		return nil
+	}
+
 	// check input's type for compatibility
 	err := f(t)
 	if err != nil {
@@ -340,7 +392,7 @@ func traverseType(t reflect.Type, f func(t reflect.Type) error) error {
 
 	// if slice, evaluate slices sub-type
 	if t.Kind() == reflect.Slice {
-		return traverseType(getSliceRootType(t), f)
+		return traverseType(getSliceRootType(t, o), f, o)
 	}
 
 	// if type isn't traversable (as it isn't a slice or struct) we have reached end of branch traversal
@@ -350,13 +402,13 @@ func traverseType(t reflect.Type, f func(t reflect.Type) error) error {
 
 	// if struct, traverse each sub-field
 	for i := 0; i < t.NumField(); i++ {
-		// if the field isn't tagged for goscanql, ignore
-		if !isGoscanqlField(t.Field(i)) {
+		// if the field isn't mapped by goscanql, ignore
+		if !isGoscanqlField(t, i, o) {
 			continue
 		}
 
 		// traverse field's subtypes
-		err := traverseType(t.Field(i).Type, f)
+		err := traverseType(t.Field(i).Type, f, o)
 		if err != nil {
 			return err
 		}
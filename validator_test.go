@@ -2,6 +2,7 @@ package goscanql
 
 import (
 	"fmt"
+	"net"
 	"reflect"
 	"testing"
 
@@ -595,6 +596,7 @@ func TestValidateType(t *testing.T) {
 	tests := []struct {
 		name     string
 		input    interface{}
+		opts     []Option
 		expected error
 	}{
 		{
@@ -691,6 +693,37 @@ func TestValidateType(t *testing.T) {
 			}{},
 			expected: fmt.Errorf("multi-dimensional slices are not supported ([][]goscanql.multidimensionalSliceScanner), consider using a slice instead"),
 		},
+		{
+			name: "StructWithSliceOfUnmarshalerSlice_NoError",
+			input: struct {
+				IPs []net.IP `sql:"ip"`
+			}{},
+			expected: nil,
+		},
+		{
+			name: "StructWithSliceOfUnmarshalerArray_NoError",
+			input: struct {
+				IDs []*converterTestUUID `sql:"id"`
+			}{},
+			expected: nil,
+		},
+		{
+			name: "StructWithSliceOfConverterSlice_NoError",
+			input: struct {
+				Paths []converterTestPath `sql:"path"`
+			}{},
+			opts: []Option{WithConverter(func(src any) (converterTestPath, error) {
+				return converterTestPath{fmt.Sprint(src)}, nil
+			})},
+			expected: nil,
+		},
+		{
+			name: "StructWithSliceOfSliceWithoutConverter_ProducesError",
+			input: struct {
+				Paths []converterTestPath `sql:"path"`
+			}{},
+			expected: fmt.Errorf("multi-dimensional slices are not supported ([]goscanql.converterTestPath), consider using a slice instead"),
+		},
 		{
 			name: "StructWithAnyInterfaceAsField_NoError",
 			input: struct {
@@ -710,7 +743,7 @@ func TestValidateType(t *testing.T) {
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			// Act
-			result := validateType(test.input)
+			result := validateType(test.input, newOptions(test.opts))
 
 			// Assert
 			assert.Equal(t, test.expected, result)
@@ -792,6 +825,11 @@ func TestGetSliceRootType(t *testing.T) {
 			input:    [][]int{},
 			expected: 1, // int
 		},
+		{
+			name:     "SliceOfUnmarshalerSlices_ReturnsUnmarshalerType",
+			input:    []net.IP{},
+			expected: net.IP{},
+		},
 	}
 
 	for _, test := range tests {
@@ -801,7 +839,7 @@ func TestGetSliceRootType(t *testing.T) {
 			expected := reflect.TypeOf(test.expected)
 
 			// Act
-			result := getSliceRootType(input)
+			result := getSliceRootType(input, nil)
 
 			// Assert
 			assert.Equal(t, expected, result)
@@ -850,7 +888,7 @@ func TestVerifyNoCycles(t *testing.T) {
 			input := reflect.TypeOf(test.input)
 
 			// Act
-			result := verifyNoCycles(input)
+			result := verifyNoCycles(input, nil)
 
 			// Assert
 			assert.Equal(t, test.expected, result)